@@ -0,0 +1,197 @@
+package govm
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const aliasesFile = "aliases.json"
+
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// Alias is a name for a version, e.g. legacy for 1.19.13. An alias of a
+// selector such as 1.21.x keeps the version it resolved to until UpdateAliases.
+type Alias struct {
+	Name      string    `json:"name"`
+	Selector  string    `json:"selector"`
+	Version   string    `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Aliases lists the aliases by name
+func (g *GoVM) Aliases() ([]Alias, error) {
+	aliases, err := g.readAliases()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Alias, 0, len(aliases))
+	for _, a := range aliases {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// SetAlias points name at the version selector resolves to. Names that
+// are selectors themselves, like latest or 1.21, are refused.
+func (g *GoVM) SetAlias(name, selector string) (Alias, error) {
+	if !aliasNamePattern.MatchString(name) {
+		return Alias{}, errors.New(T("alias.bad_name", name))
+	}
+	if _, err := ParseSelector(name); err == nil {
+		return Alias{}, errors.New(T("alias.selector_name", name))
+	}
+	aliases, err := g.readAliases()
+	if err != nil {
+		return Alias{}, err
+	}
+	// an alias of an alias copies its selector, chains are not followed later
+	if a, ok := aliases[selector]; ok {
+		selector = a.Selector
+	}
+	version, err := g.resolveVersion(selector)
+	if err != nil {
+		return Alias{}, err
+	}
+	a := Alias{Name: name, Selector: selector, Version: version, UpdatedAt: time.Now().UTC()}
+	aliases[name] = a
+	return a, g.writeAliases(aliases)
+}
+
+// RemoveAlias deletes the alias name
+func (g *GoVM) RemoveAlias(name string) error {
+	aliases, err := g.readAliases()
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[name]; !ok {
+		return errors.New(T("alias.unknown", name))
+	}
+	delete(aliases, name)
+	return g.writeAliases(aliases)
+}
+
+// UpdateAliases resolves the selectors of the given aliases again, or of
+// all aliases without names, and returns the aliases whose version changed
+func (g *GoVM) UpdateAliases(names ...string) ([]Alias, error) {
+	aliases, err := g.readAliases()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	var changed []Alias
+	for _, name := range names {
+		a, ok := aliases[name]
+		if !ok {
+			return nil, errors.New(T("alias.unknown", name))
+		}
+		if sel, err := ParseSelector(a.Selector); err == nil {
+			if _, exact := sel.Exact(); exact {
+				continue
+			}
+		}
+		version, err := g.resolveVersion(a.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if version != a.Version {
+			a.Version, a.UpdatedAt = version, time.Now().UTC()
+			aliases[name] = a
+			changed = append(changed, a)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return changed, g.writeAliases(aliases)
+}
+
+// moveAliases points the aliases of version from at version to, an alias
+// set to exactly from is set to to
+func (g *GoVM) moveAliases(from, to string) ([]Alias, error) {
+	aliases, err := g.readAliases()
+	if err != nil {
+		return nil, err
+	}
+	var moved []Alias
+	for name, a := range aliases {
+		if a.Version != from {
+			continue
+		}
+		if a.Selector == from {
+			a.Selector = to
+		}
+		a.Version, a.UpdatedAt = to, time.Now().UTC()
+		aliases[name] = a
+		moved = append(moved, a)
+	}
+	if len(moved) == 0 {
+		return nil, nil
+	}
+	sort.Slice(moved, func(i, j int) bool { return moved[i].Name < moved[j].Name })
+	return moved, g.writeAliases(aliases)
+}
+
+// aliasVersion is the version of the alias name
+func (g *GoVM) aliasVersion(name string) (string, bool) {
+	aliases, err := g.readAliases()
+	if err != nil {
+		return "", false
+	}
+	a, ok := aliases[name]
+	return a.Version, ok
+}
+
+// aliasesByVersion maps every version to the names of its aliases
+func (g *GoVM) aliasesByVersion() map[string][]string {
+	byVersion := make(map[string][]string)
+	aliases, _ := g.Aliases()
+	for _, a := range aliases {
+		byVersion[a.Version] = append(byVersion[a.Version], a.Name)
+	}
+	return byVersion
+}
+
+func (g *GoVM) readAliases() (map[string]Alias, error) {
+	aliases := make(map[string]Alias)
+	//#nosec G304
+	data, err := os.ReadFile(filepath.Join(g.installDir, aliasesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, err
+	}
+	for name, a := range aliases {
+		a.Name = name
+		aliases[name] = a
+	}
+	return aliases, nil
+}
+
+func (g *GoVM) writeAliases(aliases map[string]Alias) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(g.installDir, os.ModePerm); err != nil {
+		return err
+	}
+	path := filepath.Join(g.installDir, aliasesFile)
+	err = writeFileAtomic(path, data, 0644)
+	traceFS("write", path, err)
+	return err
+}
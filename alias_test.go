@@ -0,0 +1,74 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "versions", "1.19.13", "go", "bin"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(tags map[string][]string) { githubTags = tags }(githubTags)
+	githubTags = map[string][]string{"golang/go": {"go1.19.13", "go1.21.5", "go1.21.6", "go1.22rc1"}}
+
+	for _, name := range []string{"latest", "oldstable", "1.21", "1.21x", "-x", "a b"} {
+		if _, err := g.SetAlias(name, "1.19.13"); err == nil {
+			t.Errorf("alias %q accepted", name)
+		}
+	}
+	if _, err := g.SetAlias("legacy", "1.19.13"); err != nil {
+		t.Fatal(err)
+	}
+	prod, err := g.SetAlias("prod", "1.21.x")
+	if err != nil || prod.Version != "1.21.6" || prod.Selector != "1.21.x" {
+		t.Fatalf("prod = %+v, %v", prod, err)
+	}
+	if a, err := g.SetAlias("old", "legacy"); err != nil || a.Selector != "1.19.13" {
+		t.Errorf("alias of an alias = %+v, %v", a, err)
+	}
+	if v, err := g.resolveVersion("legacy"); err != nil || v != "1.19.13" {
+		t.Errorf("resolveVersion(legacy) = %q, %v", v, err)
+	}
+
+	githubTags = map[string][]string{"golang/go": {"go1.19.13", "go1.21.6", "go1.21.7"}}
+	changed, err := g.UpdateAliases()
+	if err != nil || len(changed) != 1 || changed[0].Name != "prod" || changed[0].Version != "1.21.7" {
+		t.Errorf("UpdateAliases = %+v, %v", changed, err)
+	}
+	if _, err := g.UpdateAliases("missing"); err == nil {
+		t.Error("updated a missing alias")
+	}
+
+	versions, err := g.InstalledVersions(false)
+	if err != nil || len(versions) != 1 || !reflect.DeepEqual(versions[0].Aliases, []string{"legacy", "old"}) {
+		t.Errorf("InstalledVersions = %+v, %v", versions, err)
+	}
+
+	if err := g.RemoveAlias("old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RemoveAlias("old"); err == nil {
+		t.Error("removed a missing alias")
+	}
+	aliases, err := g.Aliases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, a := range aliases {
+		names = append(names, a.Name+"="+a.Version)
+	}
+	if !reflect.DeepEqual(names, []string{"legacy=1.19.13", "prod=1.21.7"}) {
+		t.Errorf("Aliases = %v", names)
+	}
+}
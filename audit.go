@@ -0,0 +1,453 @@
+package govm
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+const vulnDBFile = "vulndb.json"
+
+// vulnDBURL is the archive of every entry of the Go vulnerability database
+var vulnDBURL = "https://vuln.go.dev/vulndb.zip"
+
+// supportedMinors is how many minor versions the Go team supports
+const supportedMinors = 2
+
+// AuditOptions selects where the vulnerability database and the supported
+// minor versions come from
+type AuditOptions struct {
+	// Refresh downloads the vulnerability database again
+	Refresh bool
+	// DB is a vulndb.zip or a JSON file of OSV entries replacing the cached database
+	DB string
+	// Offline neither downloads the database nor fetches the published versions,
+	// the supported minor versions are then taken from the last update check
+	// and the installed versions
+	Offline bool
+}
+
+// AuditReport lists the installed versions with their findings
+type AuditReport struct {
+	// Supported are the minor versions still supported, oldest first,
+	// empty when they are not known
+	Supported []string `json:"supported"`
+	// VulnDB is where the cached vulnerability database came from and when,
+	// nil without one
+	VulnDB   *VulnDBInfo      `json:"vulndb"`
+	Versions []AuditedVersion `json:"versions"`
+}
+
+// VulnDBInfo tells where the cached vulnerability database came from
+type VulnDBInfo struct {
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Entries   int       `json:"entries"`
+}
+
+// AuditedVersion is an installed version with the reasons it should not be used
+type AuditedVersion struct {
+	Version string `json:"version"`
+	Store   string `json:"store"`
+	Active  bool   `json:"active"`
+	// EOL tells that the minor version is older than the supported ones
+	EOL   bool   `json:"eol"`
+	Vulns []Vuln `json:"vulns"`
+}
+
+// Affected tells whether the version is past end of support or vulnerable
+func (a AuditedVersion) Affected() bool {
+	return a.EOL || len(a.Vulns) > 0
+}
+
+// Vuln is a vulnerability of the standard library or the toolchain
+type Vuln struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+	// Fixed is the first later release with the fix, empty without one
+	Fixed string `json:"fixed,omitempty"`
+}
+
+// vulnDB is the cached vulnerability database, trimmed to the entries
+// affecting the standard library and the toolchain
+type vulnDB struct {
+	Source    string     `json:"source"`
+	FetchedAt time.Time  `json:"fetched_at"`
+	Entries   []osvEntry `json:"entries"`
+}
+
+// osvEntry holds the fields of an OSV entry audit needs,
+// see https://ossf.github.io/osv-schema/
+type osvEntry struct {
+	ID       string        `json:"id"`
+	Aliases  []string      `json:"aliases,omitempty"`
+	Summary  string        `json:"summary,omitempty"`
+	Affected []osvAffected `json:"affected"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges []osvRange `json:"ranges"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced string `json:"introduced,omitempty"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+// Audit checks the installed versions against the supported minor versions
+// and the vulnerability database, downloading the database when it is not
+// cached yet
+func (g *GoVM) Audit(opts AuditOptions) (AuditReport, error) {
+	var report AuditReport
+	installed, err := g.InstalledVersions(false)
+	if err != nil {
+		return report, err
+	}
+
+	var db *vulnDB
+	switch {
+	case opts.DB != "":
+		db, err = g.importVulnDB(opts.DB)
+	case opts.Refresh && !opts.Offline:
+		db, err = g.fetchVulnDB()
+	default:
+		db, err = g.readVulnDB()
+		if os.IsNotExist(err) {
+			db, err = nil, nil
+			if !opts.Offline {
+				db, err = g.fetchVulnDB()
+			}
+		}
+	}
+	if err != nil {
+		return report, err
+	}
+	if db != nil {
+		report.VulnDB = &VulnDBInfo{Source: db.Source, FetchedAt: db.FetchedAt, Entries: len(db.Entries)}
+	}
+
+	if opts.Offline {
+		report.Supported = g.knownSupportedMinors(installed)
+	} else {
+		InfoT("remote.fetching")
+		report.Supported = latestMinors(g.remoteVersionNames())
+	}
+
+	report.Versions = make([]AuditedVersion, 0, len(installed))
+	for _, v := range installed {
+		vulns := db.affecting(v.Version)
+		if vulns == nil {
+			vulns = []Vuln{}
+		}
+		report.Versions = append(report.Versions, AuditedVersion{
+			Version: v.Version,
+			Store:   v.Store,
+			Active:  v.Active,
+			EOL:     endOfSupport(v.Version, report.Supported),
+			Vulns:   vulns,
+		})
+	}
+	return report, nil
+}
+
+// auditInstalled marks the installed versions past end of support or affected
+// by a vulnerability of the cached database, without going online
+func (g *GoVM) auditInstalled(versions []InstalledVersion) {
+	db, _ := g.readVulnDB()
+	supported := g.knownSupportedMinors(versions)
+	for i := range versions {
+		versions[i].EOL = endOfSupport(versions[i].Version, supported)
+		for _, vuln := range db.affecting(versions[i].Version) {
+			versions[i].Vulns = append(versions[i].Vulns, vuln.ID)
+		}
+	}
+}
+
+// knownSupportedMinors guesses the supported minor versions from the last
+// update check and the installed versions
+func (g *GoVM) knownSupportedMinors(installed []InstalledVersion) []string {
+	var names []string
+	if c, err := g.ReadUpdateCheck(); err == nil {
+		for _, v := range c.Go {
+			names = append(names, v)
+		}
+	}
+	for _, v := range installed {
+		names = append(names, v.Version)
+	}
+	return latestMinors(names)
+}
+
+// latestMinors are the last supportedMinors minor versions with a stable
+// release, oldest first
+func latestMinors(names []string) []string {
+	var minors []string
+	for _, group := range groupVersions(names) {
+		for _, v := range group.Versions {
+			if v.Stability == StabilityStable {
+				minors = append(minors, group.Minor)
+				break
+			}
+		}
+	}
+	if len(minors) > supportedMinors {
+		minors = minors[len(minors)-supportedMinors:]
+	}
+	return minors
+}
+
+// endOfSupport tells whether version is older than the supported minor versions,
+// prereleases of later minor versions are not
+func endOfSupport(version string, supported []string) bool {
+	if len(supported) < supportedMinors {
+		return false
+	}
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	oldest, _ := ParseVersion(supported[0])
+	minor, _ := ParseVersion(v.MinorVersion())
+	return minor.Compare(oldest) < 0
+}
+
+// affecting lists the vulnerabilities affecting version, a nil db knows none
+func (db *vulnDB) affecting(version string) []Vuln {
+	if db == nil {
+		return nil
+	}
+	v, err := ParseVersion(version)
+	if err != nil {
+		return nil
+	}
+	sv, err := semver.NewVersion(v.Canonical())
+	if err != nil {
+		return nil
+	}
+	var vulns []Vuln
+	for _, e := range db.Entries {
+		for _, a := range e.Affected {
+			if !goPackage(a.Package.Name) {
+				continue
+			}
+			affected, fixed := false, ""
+			for _, r := range a.Ranges {
+				if affected, fixed = r.affects(sv); affected {
+					break
+				}
+			}
+			if affected {
+				vulns = append(vulns, Vuln{ID: e.ID, Aliases: e.Aliases, Summary: e.Summary, Fixed: fixed})
+				break
+			}
+		}
+	}
+	return vulns
+}
+
+// affects walks the events of a SEMVER range in order, version is affected
+// from an introduced event until the next fixed one, which is returned
+func (r osvRange) affects(version *semver.Version) (bool, string) {
+	if r.Type != "SEMVER" {
+		return false, ""
+	}
+	affected := false
+	for _, e := range r.Events {
+		switch {
+		case e.Introduced == "0":
+			affected = true
+		case e.Introduced != "":
+			if introduced, err := semver.NewVersion(e.Introduced); err == nil && !version.LessThan(introduced) {
+				affected = true
+			}
+		case e.Fixed != "":
+			fixed, err := semver.NewVersion(e.Fixed)
+			if err != nil {
+				continue
+			}
+			if !version.LessThan(fixed) {
+				affected = false
+			} else if affected {
+				return true, goVersionName(e.Fixed)
+			}
+		}
+	}
+	return affected, ""
+}
+
+// goPackage tells whether an OSV package is the standard library or the toolchain
+func goPackage(name string) bool {
+	return name == "stdlib" || name == "toolchain"
+}
+
+// goVersionName turns the semver of a Go release used by the vulnerability
+// database back into a version name, e.g. 1.21.0-rc.2 into 1.21rc2
+func goVersionName(s string) string {
+	base, pre, _ := strings.Cut(s, "-")
+	v, err := ParseVersion(base)
+	if err != nil {
+		return s
+	}
+	if name, num, ok := strings.Cut(pre, "."); ok {
+		v.Pre = name
+		v.PreNum, _ = strconv.Atoi(num)
+		v.Patch = 0
+	}
+	return v.String()
+}
+
+func (g *GoVM) vulnDBPath() string {
+	return filepath.Join(g.cacheDir, vulnDBFile)
+}
+
+func (g *GoVM) readVulnDB() (*vulnDB, error) {
+	data, err := os.ReadFile(g.vulnDBPath())
+	traceFS("read", g.vulnDBPath(), err)
+	if err != nil {
+		return nil, err
+	}
+	var db vulnDB
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+	return &db, nil
+}
+
+func (g *GoVM) writeVulnDB(db *vulnDB) error {
+	data, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(g.cacheDir, os.ModePerm); err != nil {
+		return err
+	}
+	path := g.vulnDBPath()
+	err = writeFileAtomic(path, data, 0644)
+	traceFS("write", path, err)
+	return err
+}
+
+// fetchVulnDB downloads the vulnerability database and caches it
+func (g *GoVM) fetchVulnDB() (*vulnDB, error) {
+	InfoT("audit.fetching", vulnDBURL)
+	data, err := fetch(vulnDBURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", T("audit.fetch_failed"), err)
+	}
+	return g.cacheVulnDB(vulnDBURL, data)
+}
+
+// importVulnDB caches the vulnerability database of a local file
+func (g *GoVM) importVulnDB(path string) (*vulnDB, error) {
+	//#nosec G304
+	data, err := os.ReadFile(path)
+	traceFS("read", path, err)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", T("audit.db_invalid", path), err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return g.cacheVulnDB(abs, data)
+}
+
+func (g *GoVM) cacheVulnDB(source string, data []byte) (*vulnDB, error) {
+	entries, err := parseOSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", T("audit.db_invalid", source), err)
+	}
+	db := &vulnDB{Source: source, FetchedAt: time.Now().UTC(), Entries: entries}
+	if err := g.writeVulnDB(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// parseOSV reads the OSV entries of a vulndb.zip, a JSON array of entries or
+// a single entry, keeping the ones affecting the standard library or the toolchain
+func parseOSV(data []byte) ([]osvEntry, error) {
+	var entries []osvEntry
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range r.File {
+			// the entries are ID/GO-2023-1234.json, index/ holds the indexes
+			if !strings.HasPrefix(f.Name, "ID/") || !strings.HasSuffix(f.Name, ".json") {
+				continue
+			}
+			e, err := readZipEntry(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			entries = append(entries, e)
+		}
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var e osvEntry
+		if err := json.Unmarshal(trimmed, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	default:
+		return nil, errors.New(T("audit.db_format"))
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		affected := e.Affected[:0]
+		for _, a := range e.Affected {
+			if goPackage(a.Package.Name) {
+				affected = append(affected, a)
+			}
+		}
+		if len(affected) > 0 {
+			e.Affected = affected
+			kept = append(kept, e)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].ID < kept[j].ID
+	})
+	return kept, nil
+}
+
+func readZipEntry(f *zip.File) (osvEntry, error) {
+	var e osvEntry
+	rc, err := f.Open()
+	if err != nil {
+		return e, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	err = json.NewDecoder(rc).Decode(&e)
+	return e, err
+}
@@ -0,0 +1,205 @@
+package govm
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testOSV = `[
+  {
+    "id": "GO-2023-2041",
+    "aliases": ["CVE-2023-39323"],
+    "summary": "Arbitrary code execution during build via line directives in cmd/go",
+    "affected": [{
+      "package": {"name": "toolchain", "ecosystem": "Go"},
+      "ranges": [{"type": "SEMVER", "events": [
+        {"introduced": "0"}, {"fixed": "1.20.9"},
+        {"introduced": "1.21.0-0"}, {"fixed": "1.21.2"}
+      ]}]
+    }]
+  },
+  {
+    "id": "GO-2024-2687",
+    "summary": "HTTP/2 CONTINUATION flood in net/http",
+    "affected": [{
+      "package": {"name": "stdlib", "ecosystem": "Go"},
+      "ranges": [{"type": "SEMVER", "events": [
+        {"introduced": "0"}, {"fixed": "1.21.9"},
+        {"introduced": "1.22.0-0"}, {"fixed": "1.22.2"}
+      ]}]
+    }]
+  },
+  {
+    "id": "GO-2022-0001",
+    "summary": "not the standard library",
+    "affected": [{
+      "package": {"name": "golang.org/x/net", "ecosystem": "Go"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]
+    }]
+  }
+]`
+
+func TestVulnDBAffecting(t *testing.T) {
+	entries, err := parseOSV([]byte(testOSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("kept %d entries, want the 2 of stdlib and toolchain", len(entries))
+	}
+	db := &vulnDB{Entries: entries}
+	tests := []struct {
+		version string
+		want    map[string]string
+	}{
+		{"1.20.8", map[string]string{"GO-2023-2041": "1.20.9", "GO-2024-2687": "1.21.9"}},
+		{"1.20.9", map[string]string{"GO-2024-2687": "1.21.9"}},
+		{"1.21rc2", map[string]string{"GO-2023-2041": "1.21.2", "GO-2024-2687": "1.21.9"}},
+		{"1.21.9", map[string]string{}},
+		{"1.22rc1", map[string]string{"GO-2024-2687": "1.22.2"}},
+		{"1.22.2", map[string]string{}},
+	}
+	for _, tt := range tests {
+		got := make(map[string]string)
+		for _, v := range db.affecting(tt.version) {
+			got[v.ID] = v.Fixed
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("affecting(%s) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+	if (*vulnDB)(nil).affecting("1.20.8") != nil {
+		t.Error("a missing database knows vulnerabilities")
+	}
+	if got := goVersionName("1.21.0-rc.2"); got != "1.21rc2" {
+		t.Errorf("goVersionName = %s", got)
+	}
+}
+
+func TestEndOfSupport(t *testing.T) {
+	supported := latestMinors([]string{"1.20.5", "1.21.6", "1.22.1", "1.23rc1"})
+	if !reflect.DeepEqual(supported, []string{"1.21", "1.22"}) {
+		t.Fatalf("latestMinors = %v", supported)
+	}
+	for version, want := range map[string]bool{
+		"1.20.14": true,
+		"1.21.0":  false,
+		"1.23rc1": false,
+		"1":       true,
+	} {
+		if got := endOfSupport(version, supported); got != want {
+			t.Errorf("endOfSupport(%s) = %v, want %v", version, got, want)
+		}
+	}
+	if endOfSupport("1.16", []string{"1.22"}) {
+		t.Error("end of support without knowing the supported versions")
+	}
+}
+
+func TestAudit(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	home := t.TempDir()
+	for _, v := range []string{"1.20.5", "1.21.9", "1.22.1"} {
+		if err := os.MkdirAll(filepath.Join(home, "versions", v, "go", "bin"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.22.1")
+
+	// the database comes as the published archive
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	entries, err := parseOSV([]byte(testOSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		w, err := zw.Create("ID/" + e.ID + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewEncoder(w).Encode(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w, err := zw.Create("index/db.json"); err != nil {
+		t.Fatal(err)
+	} else if _, err := w.Write([]byte(`{"modified": "2024-04-03T00:00:00Z"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db := filepath.Join(t.TempDir(), "vulndb.zip")
+	if err := os.WriteFile(db, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := g.Audit(AuditOptions{DB: db, Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Supported, []string{"1.21", "1.22"}) {
+		t.Errorf("supported = %v", report.Supported)
+	}
+	if report.VulnDB == nil || report.VulnDB.Source != db || report.VulnDB.Entries != 2 {
+		t.Errorf("vulndb = %+v", report.VulnDB)
+	}
+	got := make(map[string][]string)
+	for _, v := range report.Versions {
+		var findings []string
+		if v.EOL {
+			findings = append(findings, "eol")
+		}
+		for _, vuln := range v.Vulns {
+			findings = append(findings, vuln.ID)
+		}
+		got[v.Version] = findings
+	}
+	want := map[string][]string{
+		"1.20.5": {"eol", "GO-2023-2041", "GO-2024-2687"},
+		"1.21.9": nil,
+		"1.22.1": {"GO-2024-2687"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+
+	// list marks the versions with the cached database
+	versions, err := g.InstalledVersions(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range versions {
+		marked := v.EOL || len(v.Vulns) > 0
+		if marked != (want[v.Version] != nil) {
+			t.Errorf("%s marked = %v", v.Version, marked)
+		}
+	}
+
+	// offline without a database checks the end of support only
+	if err := os.Remove(g.vulnDBPath()); err != nil {
+		t.Fatal(err)
+	}
+	report, err = g.Audit(AuditOptions{Offline: true})
+	if err != nil || report.VulnDB != nil || !report.Versions[0].EOL || len(report.Versions[2].Vulns) != 0 {
+		t.Errorf("offline audit = %+v, %v", report, err)
+	}
+
+	if _, err := g.Audit(AuditOptions{DB: filepath.Join(home, "versions", "1.22.1", "go", "bin")}); err == nil {
+		t.Error("a directory is not a database")
+	}
+}
@@ -0,0 +1,46 @@
+// govm-sign signs the checksums file of a govm release.
+//
+//	govm-sign keygen                  print a new key pair
+//	govm-sign <file> <signature file> sign file with the key in GOVM_SIGNING_KEY
+//
+// The public key is compiled into govm with
+// -ldflags "-X govm.releasePublicKey=<public key>" and used by self-update
+// to verify the checksums before replacing the binary.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	log.SetFlags(0)
+	switch {
+	case len(os.Args) == 2 && os.Args[1] == "keygen":
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("GOVM_SIGNING_KEY=%s\n", base64.StdEncoding.EncodeToString(priv.Seed()))
+		fmt.Printf("GOVM_RELEASE_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(pub))
+	case len(os.Args) == 3:
+		seed, err := base64.StdEncoding.DecodeString(os.Getenv("GOVM_SIGNING_KEY"))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			log.Fatal("GOVM_SIGNING_KEY must be a base64 encoded ed25519 seed, see govm-sign keygen")
+		}
+		data, err := os.ReadFile(os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		sig := ed25519.Sign(ed25519.NewKeyFromSeed(seed), data)
+		if err := os.WriteFile(os.Args[2], []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0644); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal("usage: govm-sign keygen | govm-sign <file> <signature file>")
+	}
+}
@@ -0,0 +1,477 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"govm"
+
+	cs "github.com/mitchellh/colorstring"
+)
+
+// globalFlags are accepted before and after any subcommand
+type globalFlags struct {
+	home     string
+	registry string
+	system   bool
+	quiet    bool
+	verbose  bool
+	json     bool
+	noColor  bool
+	help     bool
+	// debug, logFormat and logFile add to the GOVM_LOG settings
+	debug     bool
+	logFormat string
+	logFile   bool
+}
+
+// register adds the global flags to fs, keeping the values parsed so far
+// so flags given before the subcommand survive its own flag set
+func (gf *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&gf.home, "home", gf.home, govm.T("flag.home"))
+	fs.StringVar(&gf.registry, "registry", gf.registry, govm.T("flag.registry"))
+	fs.BoolVar(&gf.system, "system", gf.system, govm.T("flag.system"))
+	fs.BoolVar(&gf.quiet, "quiet", gf.quiet, govm.T("flag.quiet"))
+	fs.BoolVar(&gf.verbose, "verbose", gf.verbose, govm.T("flag.verbose"))
+	fs.BoolVar(&gf.json, "json", gf.json, govm.T("flag.json"))
+	fs.BoolVar(&gf.noColor, "no-color", gf.noColor, govm.T("flag.no-color"))
+	fs.BoolVar(&gf.debug, "debug", gf.debug, govm.T("flag.debug"))
+	fs.StringVar(&gf.logFormat, "log-format", gf.logFormat, govm.T("flag.log-format"))
+	fs.BoolVar(&gf.logFile, "log-file", gf.logFile, govm.T("flag.log-file"))
+	fs.BoolVar(&gf.help, "help", gf.help, govm.T("flag.help"))
+	fs.BoolVar(&gf.help, "h", gf.help, govm.T("flag.help"))
+}
+
+// command is a single node of the govm command tree
+type command struct {
+	name    string
+	aliases []string
+	// args and summary are message keys
+	args    string
+	summary string
+	// minArgs and maxArgs bound the positional arguments, maxArgs < 0 means unbounded
+	minArgs int
+	maxArgs int
+	// json marks commands that can print their result as JSON
+	json bool
+	// hidden commands are neither listed nor suggested
+	hidden bool
+	setup  func(fs *flag.FlagSet)
+	run    func(ctx *context, args []string) error
+}
+
+// context carries what every command needs to run
+type context struct {
+	flags *globalFlags
+	govm  *govm.GoVM
+	out   io.Writer
+}
+
+// usageError is reported with exit status 2 and a pointer to the help
+type usageError struct {
+	cmd string
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// exitStatus passes on the exit status of a command run by govm, which
+// reported its own errors
+type exitStatus int
+
+func (e exitStatus) Error() string {
+	return "exit status " + strconv.Itoa(int(e))
+}
+
+// synopsis is the name followed by the localized arguments
+func (c *command) synopsis() string {
+	if c.args == "" {
+		return c.name
+	}
+	return c.name + " " + govm.T(c.args)
+}
+
+func (c *command) matches(name string) bool {
+	if c.name == name {
+		return true
+	}
+	for _, alias := range c.aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.matches(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// run parses args and dispatches to the matching command, returning the exit status
+func run(args []string) int {
+	gf := &globalFlags{}
+	top := flag.NewFlagSet("govm", flag.ContinueOnError)
+	top.SetOutput(io.Discard)
+	gf.register(top)
+	if err := top.Parse(args); err != nil {
+		return reportError(err)
+	}
+	// config errors are reported once the final --home is known
+	_ = applyGlobalFlags(gf)
+
+	rest := top.Args()
+	if len(rest) == 0 {
+		printUsage()
+		if gf.help {
+			return 0
+		}
+		return 2
+	}
+
+	name := rest[0]
+	c := lookupCommand(name)
+	if c == nil {
+		return reportError(&usageError{msg: unknownCommandMessage(name)})
+	}
+
+	fs := newFlagSet(gf, c)
+	positional, err := parseInterspersed(fs, rest[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		gf.help = true
+	} else if err != nil {
+		return reportError(&usageError{cmd: c.name, msg: err.Error()})
+	}
+	// a broken config must not keep 'govm config set' from repairing it
+	if err := applyGlobalFlags(gf); err != nil && !(c.name == "config" && len(positional) > 0 && positional[0] == "set") {
+		return reportError(err)
+	}
+
+	if gf.help {
+		printCommandUsage(c, fs)
+		return 0
+	}
+	if len(positional) < c.minArgs {
+		return reportError(&usageError{cmd: c.name, msg: govm.T("cli.missing_args", c.name, govm.T(c.args))})
+	}
+	if c.maxArgs >= 0 && len(positional) > c.maxArgs {
+		return reportError(&usageError{cmd: c.name, msg: govm.T("cli.too_many_args", c.name, strings.Join(positional[c.maxArgs:], " "))})
+	}
+	if gf.json && !c.json {
+		return reportError(&usageError{cmd: c.name, msg: govm.T("cli.no_json", c.name)})
+	}
+
+	g, err := govm.NewGoVmWithOptions(govm.Options{Home: gf.home, Registry: gf.registry, System: gf.system})
+	if err != nil {
+		return reportError(err)
+	}
+	logPath, stopTrace, err := startTrace(gf, g.Env().LogsDir)
+	if err != nil {
+		return reportError(&usageError{cmd: c.name, msg: err.Error()})
+	}
+	defer func() {
+		_ = stopTrace()
+		if logPath != "" {
+			govm.InfoT("trace.written", logPath)
+		}
+	}()
+	govm.CurrentTracer().Event("command", "name", c.name, "args", strings.Join(positional, " "), "version", version)
+
+	ctx := &context{flags: gf, govm: &g, out: os.Stdout}
+	if err := c.run(ctx, positional); err != nil {
+		return reportError(err)
+	}
+	notifyUpdates(ctx, c)
+	return 0
+}
+
+// notifyUpdates prints the newer releases known from the update check cache
+// and refreshes the cache in a background process once a day, so commands
+// never wait for the network
+func notifyUpdates(ctx *context, c *command) {
+	switch c.name {
+	case "self-update", "version", "help", "exec", "__update-check":
+		return
+	}
+	if !ctx.govm.UpdateCheckEnabled() || ctx.flags.json || ctx.flags.quiet || !govm.IsTerminal(os.Stderr) {
+		return
+	}
+
+	// notices go to stderr so the output of commands stays parseable
+	r := govm.CurrentReporter()
+	for _, notice := range ctx.govm.UpdateNotices(version) {
+		if r.ErrColor {
+			notice = govm.ColorInfo.Sprint(notice)
+		}
+		_, _ = fmt.Fprint(r.Err, notice)
+	}
+
+	if ctx.govm.UpdateCheckDue() {
+		self, err := os.Executable()
+		if err != nil {
+			return
+		}
+		args := []string{"__update-check"}
+		if ctx.flags.home != "" {
+			args = append(args, "--home", ctx.flags.home)
+		}
+		// #nosec G204
+		cmd := exec.Command(self, args...)
+		if err := cmd.Start(); err == nil {
+			_ = cmd.Process.Release()
+		}
+	}
+}
+
+// startTrace combines GOVM_LOG with the --debug, --log-format and --log-file flags
+func startTrace(gf *globalFlags, logsDir string) (string, func() error, error) {
+	opts := govm.TraceOptions{Dir: logsDir}
+	if err := govm.ParseTraceEnv(os.Getenv("GOVM_LOG"), &opts); err != nil {
+		return "", nil, err
+	}
+	opts.Enabled = opts.Enabled || gf.debug
+	opts.File = opts.File || gf.logFile
+	if gf.logFormat != "" {
+		opts.Format = gf.logFormat
+	}
+	return govm.StartTrace(opts, os.Stderr)
+}
+
+func newFlagSet(gf *globalFlags, c *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	gf.register(fs)
+	if c.setup != nil {
+		c.setup(fs)
+	}
+	return fs
+}
+
+func runHelp(ctx *context, c *command) error {
+	printCommandUsage(c, newFlagSet(&globalFlags{}, c))
+	return nil
+}
+
+// parseInterspersed lets flags appear before, between and after positional
+// arguments, everything after a literal "--" is passed through untouched
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var passthrough []string
+	for i, arg := range args {
+		if arg == "--" {
+			passthrough = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return append(positional, passthrough...), nil
+}
+
+// applyGlobalFlags sets up the reporter and locale from the flags and the config
+// of the selected home, the defaults are used when the config is invalid
+func applyGlobalFlags(gf *globalFlags) error {
+	cfg := govm.DefaultConfig()
+	layout, err := govm.ResolveLayout(gf.home)
+	if err == nil {
+		if cfg, err = govm.LoadConfig(layout); err != nil {
+			cfg = govm.DefaultConfig()
+		}
+	}
+	if cfg.UI.Locale != "" {
+		govm.SetLocale(cfg.UI.Locale)
+	}
+
+	level := govm.LevelNormal
+	switch {
+	case gf.quiet || gf.json:
+		// keep status messages out of JSON output
+		level = govm.LevelQuiet
+	case gf.verbose:
+		level = govm.LevelVerbose
+	}
+	r := govm.NewReporter(os.Stdout, os.Stderr, level, gf.noColor || cfg.UI.Color == govm.ColorNever)
+	if cfg.UI.Color == govm.ColorAlways && !gf.noColor {
+		r.Color, r.ErrColor = true, true
+	}
+	govm.SetReporter(r)
+	return err
+}
+
+func reportError(err error) int {
+	var status exitStatus
+	if errors.As(err, &status) {
+		return int(status)
+	}
+	var ue *usageError
+	if errors.As(err, &ue) {
+		govm.ErrorT("cli.error", ue.msg)
+		if ue.cmd != "" {
+			govm.ErrorT("cli.see_command_help", ue.cmd)
+		} else {
+			govm.ErrorT("cli.see_help")
+		}
+		return 2
+	}
+	govm.ErrorT("cli.error", err)
+	return 1
+}
+
+func unknownCommandMessage(name string) string {
+	msg := govm.T("cli.unknown_command", name)
+	if suggestions := suggestCommands(name); len(suggestions) > 0 {
+		msg += "\n" + govm.T("cli.did_you_mean", strings.Join(suggestions, ", "))
+	}
+	return msg
+}
+
+// suggestCommands returns the command names close to a mistyped one
+func suggestCommands(name string) []string {
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	var suggestions []string
+	for _, c := range commands {
+		if c.hidden {
+			continue
+		}
+		for _, candidate := range append([]string{c.name}, c.aliases...) {
+			if levenshtein(name, candidate) <= maxDistance || (len(name) > 1 && strings.HasPrefix(candidate, name)) {
+				suggestions = append(suggestions, c.name)
+				break
+			}
+		}
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func colorize(s string) string {
+	c := &cs.Colorize{Colors: cs.DefaultColors, Disable: !govm.CurrentReporter().Color, Reset: true}
+	return c.Color(s)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stdout, colorize(usage()))
+}
+
+func printCommandUsage(c *command, fs *flag.FlagSet) {
+	var b strings.Builder
+	b.WriteString("\n[light_green][underline]" + govm.T("usage.heading") + "[reset]:\n")
+	b.WriteString(fmt.Sprintf("    [magenta]govm[reset] [light_gray]%s[reset]\n", c.synopsis()))
+	b.WriteString(fmt.Sprintf("    [yellow]%s[reset]\n", govm.T(c.summary)))
+	if len(c.aliases) > 0 {
+		b.WriteString(fmt.Sprintf("\n[light_green][underline]%s[reset]: %s\n", govm.T("usage.aliases"), strings.Join(c.aliases, ", ")))
+	}
+
+	global := flag.NewFlagSet("", flag.ContinueOnError)
+	(&globalFlags{}).register(global)
+	var own []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		if global.Lookup(f.Name) == nil {
+			own = append(own, f)
+		}
+	})
+	if len(own) > 0 {
+		b.WriteString("\n[light_green][underline]" + govm.T("usage.options") + "[reset]:\n")
+		for _, f := range own {
+			b.WriteString(flagLine(f))
+		}
+	}
+	b.WriteString("\n")
+	writeGlobalOptions(&b)
+	fmt.Println(colorize(b.String()))
+}
+
+// writeGlobalOptions lists the flags every command accepts
+func writeGlobalOptions(b *strings.Builder) {
+	global := flag.NewFlagSet("", flag.ContinueOnError)
+	(&globalFlags{}).register(global)
+	b.WriteString("[light_green][underline]" + govm.T("usage.global_options") + "[reset]:\n")
+	global.VisitAll(func(f *flag.Flag) {
+		if f.Name != "h" {
+			b.WriteString(flagLine(f))
+		}
+	})
+	b.WriteString(fmt.Sprintf("    [light_gray]%s[yellow]%s[reset]\n", pad(govm.T("usage.command_help"), 24), govm.T("usage.command_help_summary")))
+}
+
+func flagLine(f *flag.Flag) string {
+	name, usage := flag.UnquoteUsage(f)
+	left := "--" + f.Name
+	if name != "" {
+		left += " <" + name + ">"
+	}
+	return fmt.Sprintf("    [light_gray]%s[yellow]%s[reset]\n", pad(left, 24), usage)
+}
+
+// pad fills s with spaces up to width terminal columns, counting wide characters twice
+func pad(s string, width int) string {
+	w := 0
+	for _, r := range s {
+		w++
+		if r >= 0x1100 {
+			w++
+		}
+	}
+	if w >= width {
+		return s + " "
+	}
+	return s + strings.Repeat(" ", width-w)
+}
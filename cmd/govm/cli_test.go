@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"govm"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	cases := []struct {
+		args       []string
+		positional []string
+		quiet      bool
+	}{
+		{[]string{"1.16"}, []string{"1.16"}, false},
+		{[]string{"1.16", "--quiet"}, []string{"1.16"}, true},
+		{[]string{"--quiet", "1.16"}, []string{"1.16"}, true},
+		{[]string{"1.16", "--", "--quiet"}, []string{"1.16", "--quiet"}, false},
+	}
+	for _, c := range cases {
+		gf := &globalFlags{}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		gf.register(fs)
+		positional, err := parseInterspersed(fs, c.args)
+		if err != nil {
+			t.Fatalf("%v: %s", c.args, err)
+		}
+		if !reflect.DeepEqual(positional, c.positional) || gf.quiet != c.quiet {
+			t.Errorf("%v: got %v quiet=%v, want %v quiet=%v", c.args, positional, gf.quiet, c.positional, c.quiet)
+		}
+	}
+}
+
+func TestSuggestCommands(t *testing.T) {
+	cases := map[string][]string{
+		"isntall":   {"install"},
+		"lst":       {"list"},
+		"ls-remot":  {"ls-remote"},
+		"zzzzzzzzz": nil,
+	}
+	for typo, want := range cases {
+		if got := suggestCommands(typo); !reflect.DeepEqual(got, want) {
+			t.Errorf("suggestCommands(%q) = %v, want %v", typo, got, want)
+		}
+	}
+}
+
+func TestGlobalFlagsSurviveSubcommandFlagSet(t *testing.T) {
+	gf := &globalFlags{}
+	top := flag.NewFlagSet("govm", flag.ContinueOnError)
+	gf.register(top)
+	if err := top.Parse([]string{"--quiet", "install"}); err != nil {
+		t.Fatal(err)
+	}
+	newFlagSet(gf, lookupCommand("install"))
+	if !gf.quiet {
+		t.Error("--quiet given before the subcommand was reset")
+	}
+}
+
+func TestCommandMessagesExist(t *testing.T) {
+	for _, c := range commands {
+		for _, key := range []string{c.summary, c.args} {
+			if key != "" && govm.T(key) == key {
+				t.Errorf("command %s uses the unknown message key %q", c.name, key)
+			}
+		}
+	}
+}
+
+func TestVersionArgFollowsPin(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SHELL_VERSION", "")
+	t.Setenv("GOVM_DEFAULT_VERSION", "1.20.3")
+	g, err := govm.NewGoVmWithOptions(govm.Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := &context{flags: &globalFlags{}, govm: &g, out: io.Discard}
+	project := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	if v, err := versionArg(ctx, "use", nil); err != nil || v != "1.20.3" {
+		t.Errorf("without a pin = %q, %v", v, err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".go-version"), []byte("1.21.x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := versionArg(ctx, "use", nil); err != nil || v != "1.21.x" {
+		t.Errorf("with a pin = %q, %v", v, err)
+	}
+	t.Setenv("GOVM_SHELL_VERSION", "1.22.0")
+	if v, err := versionArg(ctx, "use", nil); err != nil || v != "1.22.0" {
+		t.Errorf("with the shell override = %q, %v", v, err)
+	}
+	if v, err := versionArg(ctx, "use", []string{"1.16"}); err != nil || v != "1.16" {
+		t.Errorf("with a version = %q, %v", v, err)
+	}
+}
@@ -0,0 +1,988 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"govm"
+)
+
+// commands is the govm command tree, in the order they are listed by help
+var commands []*command
+
+var selfUpdateOpts govm.UpgradeOptions
+
+var listLong bool
+
+var (
+	lsRemoteFilter    govm.RemoteFilter
+	lsRemoteFormat    string
+	lsRemoteInstalled bool
+)
+
+var currentExplain bool
+
+var verifyAll bool
+
+var (
+	pruneOpts govm.PrunePolicy
+	pruneYes  bool
+)
+
+var (
+	upgradeGoOpts   govm.GoUpgradeOptions
+	upgradeGoDryRun bool
+)
+
+var (
+	importScan bool
+	importMode string
+)
+
+var (
+	doctorOpts govm.DoctorOptions
+	doctorFix  bool
+)
+
+var auditOpts govm.AuditOptions
+
+var notesOpts govm.NotesOptions
+
+func init() {
+	commands = []*command{
+		{
+			name:    "use",
+			args:    "arg.version",
+			summary: "cmd.use",
+			maxArgs: 1,
+			run: func(ctx *context, args []string) error {
+				v, err := versionArg(ctx, "use", args)
+				if err != nil {
+					return err
+				}
+				ctx.govm.Install(v)
+				ctx.govm.Use(v)
+				return nil
+			},
+		},
+		{
+			name:    "list",
+			aliases: []string{"ls"},
+			summary: "cmd.list",
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&listLong, "long", false, govm.T("flag.list.long"))
+				fs.BoolVar(&listLong, "l", false, govm.T("flag.list.long"))
+			},
+			run: func(ctx *context, args []string) error {
+				if !ctx.flags.json && !listLong {
+					return ctx.govm.ListVersions()
+				}
+				versions, err := ctx.govm.InstalledVersions(true)
+				if err != nil {
+					return err
+				}
+				if !ctx.flags.json {
+					govm.PrintInstalledVersionsLong(ctx.out, versions)
+					return nil
+				}
+				if versions == nil {
+					versions = []govm.InstalledVersion{}
+				}
+				return writeJSON(ctx, listOutput{Current: ctx.govm.CurrentVersion(), Versions: versions})
+			},
+		},
+		{
+			name:    "ls-remote",
+			summary: "cmd.ls-remote",
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&lsRemoteFilter.Stable, "stable", false, govm.T("flag.ls-remote.stable"))
+				fs.BoolVar(&lsRemoteFilter.Prerelease, "prerelease", false, govm.T("flag.ls-remote.prerelease"))
+				fs.StringVar(&lsRemoteFilter.Since, "since", "", govm.T("flag.ls-remote.since"))
+				fs.StringVar(&lsRemoteFilter.Minor, "minor", "", govm.T("flag.ls-remote.minor"))
+				fs.BoolVar(&lsRemoteFilter.LatestPerMinor, "latest-per-minor", false, govm.T("flag.ls-remote.latest-per-minor"))
+				fs.StringVar(&lsRemoteFilter.AvailableFor, "available-for", "", govm.T("flag.ls-remote.available-for"))
+				fs.StringVar(&lsRemoteFormat, "format", "table", govm.T("flag.ls-remote.format"))
+				fs.BoolVar(&lsRemoteInstalled, "installed", false, govm.T("flag.ls-remote.installed"))
+			},
+			run: runLsRemote,
+		},
+		{
+			name:    "notes",
+			args:    "arg.notes",
+			summary: "cmd.notes",
+			minArgs: 1, maxArgs: 1,
+			json: true,
+			setup: func(fs *flag.FlagSet) {
+				fs.StringVar(&notesOpts.File, "file", "", govm.T("flag.notes.file"))
+			},
+			run: runNotes,
+		},
+		{
+			name:    "install",
+			args:    "arg.version",
+			summary: "cmd.install",
+			maxArgs: 1,
+			run: func(ctx *context, args []string) error {
+				v, err := versionArg(ctx, "install", args)
+				if err != nil {
+					return err
+				}
+				ctx.govm.Install(v)
+				return nil
+			},
+		},
+		{
+			name:    "uninstall",
+			args:    "arg.version",
+			summary: "cmd.uninstall",
+			minArgs: 1, maxArgs: 1,
+			run: func(ctx *context, args []string) error {
+				ctx.govm.Uninstall(args[0])
+				return nil
+			},
+		},
+		{
+			name:    "current",
+			summary: "cmd.current",
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&currentExplain, "explain", false, govm.T("flag.current.explain"))
+			},
+			run: func(ctx *context, args []string) error {
+				if currentExplain {
+					return runExplain(ctx)
+				}
+				current := ctx.govm.CurrentVersion()
+				if ctx.flags.json {
+					return writeJSON(ctx, currentOutput{Version: current, Path: ctx.govm.CurrentVersionPath()})
+				}
+				if current != "" {
+					fmt.Fprintln(ctx.out, current)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "which",
+			summary: "cmd.which",
+			json:    true,
+			run:     runWhich,
+		},
+		{
+			name:    "env",
+			summary: "cmd.env",
+			json:    true,
+			run: func(ctx *context, args []string) error {
+				env := ctx.govm.Env()
+				if ctx.flags.json {
+					return writeJSON(ctx, env)
+				}
+				fmt.Fprintf(ctx.out, "GOVM_HOME=%q\n", env.Home)
+				fmt.Fprintf(ctx.out, "GOVM_VERSIONS=%q\n", env.VersionsDir)
+				fmt.Fprintf(ctx.out, "GOVM_CURRENT=%q\n", env.CurrentDir)
+				fmt.Fprintf(ctx.out, "GOVM_REGISTRY=%q\n", env.Registry)
+				fmt.Fprintf(ctx.out, "GOVM_CONFIG=%q\n", env.Config)
+				fmt.Fprintf(ctx.out, "GOVM_SYSTEM_STORE=%q\n", env.SystemStore)
+				fmt.Fprintf(ctx.out, "GOVM_BIN=%q\n", env.BinDir)
+				fmt.Fprintf(ctx.out, "GOVM_CACHE=%q\n", env.CacheDir)
+				fmt.Fprintf(ctx.out, "GOVM_ARCH=%q\n", env.Arch)
+				fmt.Fprintf(ctx.out, "GOVM_GO_VERSION=%q\n", env.Current)
+				return nil
+			},
+		},
+		{
+			name:    "config",
+			args:    "arg.config",
+			summary: "cmd.config",
+			minArgs: 1, maxArgs: 3,
+			json: true,
+			run:  runConfig,
+		},
+		{
+			name:    "verify",
+			args:    "arg.verify",
+			summary: "cmd.verify",
+			maxArgs: 1,
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&verifyAll, "all", false, govm.T("flag.verify.all"))
+			},
+			run: runVerify,
+		},
+		{
+			name:    "repair",
+			args:    "arg.version",
+			summary: "cmd.repair",
+			minArgs: 1, maxArgs: 1,
+			run: func(ctx *context, args []string) error {
+				if err := ctx.govm.Repair(args[0]); err != nil {
+					return err
+				}
+				govm.SuccessT("repair.done", args[0])
+				return nil
+			},
+		},
+		{
+			name:    "doctor",
+			summary: "cmd.doctor",
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&doctorFix, "fix", false, govm.T("flag.doctor.fix"))
+				fs.BoolVar(&doctorOpts.Online, "online", false, govm.T("flag.doctor.online"))
+			},
+			run: runDoctor,
+		},
+		{
+			name:    "audit",
+			summary: "cmd.audit",
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&auditOpts.Refresh, "refresh", false, govm.T("flag.audit.refresh"))
+				fs.StringVar(&auditOpts.DB, "db", "", govm.T("flag.audit.db"))
+				fs.BoolVar(&auditOpts.Offline, "offline", false, govm.T("flag.audit.offline"))
+			},
+			run: runAudit,
+		},
+		{
+			name:    "exec",
+			args:    "arg.exec",
+			summary: "cmd.exec",
+			minArgs: 2, maxArgs: -1,
+			run: runExec,
+		},
+		{
+			name:    "prune",
+			summary: "cmd.prune",
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&pruneOpts.KeepLatestPatch, "keep-latest-patch", false, govm.T("flag.prune.keep-latest-patch"))
+				fs.IntVar(&pruneOpts.KeepRecent, "keep-recent", 0, govm.T("flag.prune.keep-recent"))
+				fs.Var((*stringList)(&pruneOpts.KeepPinned), "keep-pinned", govm.T("flag.prune.keep-pinned"))
+				fs.Var((*ageValue)(&pruneOpts.PrereleaseOlderThan), "prerelease-older-than", govm.T("flag.prune.prerelease-older-than"))
+				fs.BoolVar(&pruneYes, "yes", false, govm.T("flag.prune.yes"))
+			},
+			run: runPrune,
+		},
+		{
+			name:    "store",
+			args:    "arg.store",
+			summary: "cmd.store",
+			minArgs: 1, maxArgs: 1,
+			json: true,
+			run:  runStore,
+		},
+		{
+			name:    "upgrade-go",
+			summary: "cmd.upgrade-go",
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&upgradeGoOpts.CurrentOnly, "current", false, govm.T("flag.upgrade-go.current"))
+				fs.BoolVar(&upgradeGoOpts.Prune, "prune", false, govm.T("flag.upgrade-go.prune"))
+				fs.BoolVar(&upgradeGoDryRun, "dry-run", false, govm.T("flag.upgrade-go.dry-run"))
+			},
+			run: runUpgradeGo,
+		},
+		{
+			name:    "alias",
+			args:    "arg.alias",
+			summary: "cmd.alias",
+			minArgs: 1, maxArgs: 3,
+			json: true,
+			run:  runAlias,
+		},
+		{
+			name:    "import",
+			args:    "arg.import",
+			summary: "cmd.import",
+			maxArgs: 1,
+			json:    true,
+			setup: func(fs *flag.FlagSet) {
+				fs.BoolVar(&importScan, "scan", false, govm.T("flag.import.scan"))
+				fs.StringVar(&importMode, "mode", govm.ImportCopy, govm.T("flag.import.mode"))
+			},
+			run: runImport,
+		},
+		{
+			name:    "migrate-home",
+			args:    "arg.dir",
+			summary: "cmd.migrate-home",
+			maxArgs: 1,
+			run:     runMigrateHome,
+		},
+		{
+			name:    "self-update",
+			summary: "cmd.self-update",
+			setup: func(fs *flag.FlagSet) {
+				fs.StringVar(&selfUpdateOpts.Version, "version", "", govm.T("flag.self-update.version"))
+				fs.BoolVar(&selfUpdateOpts.Check, "check", false, govm.T("flag.self-update.check"))
+				fs.StringVar(&selfUpdateOpts.Channel, "channel", "", govm.T("flag.self-update.channel"))
+			},
+			run: func(ctx *context, args []string) error {
+				return ctx.govm.Upgrade(version, selfUpdateOpts)
+			},
+		},
+		{
+			name:    "version",
+			summary: "cmd.version",
+			json:    true,
+			run: func(ctx *context, args []string) error {
+				if ctx.flags.json {
+					return writeJSON(ctx, map[string]string{"version": version})
+				}
+				fmt.Fprintln(ctx.out, version)
+				return nil
+			},
+		},
+		{
+			// refreshes the update check cache, started in the background by notifyUpdates
+			name:   "__update-check",
+			hidden: true,
+			run: func(ctx *context, args []string) error {
+				return ctx.govm.RefreshUpdateCheck()
+			},
+		},
+		{
+			name:    "help",
+			aliases: []string{"h"},
+			args:    "arg.command",
+			summary: "cmd.help",
+			maxArgs: 1,
+			run: func(ctx *context, args []string) error {
+				if len(args) == 0 {
+					printUsage()
+					return nil
+				}
+				c := lookupCommand(args[0])
+				if c == nil {
+					return &usageError{msg: unknownCommandMessage(args[0])}
+				}
+				return runHelp(ctx, c)
+			},
+		},
+	}
+}
+
+// versionArg is the version given to c, or else the version for the working
+// directory when one is pinned, or else the configured defaults.version
+func versionArg(ctx *context, c string, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	// the version which and current --explain show for the working directory
+	if dir, err := os.Getwd(); err == nil {
+		if e := ctx.govm.Explain(dir); e.Origin != govm.OriginGlobal && e.Selector != "" {
+			govm.InfoT("cli.version_from", e.Selector, e.OriginPath)
+			return e.Selector, nil
+		}
+	}
+	if v := ctx.govm.Config().Defaults.Version; v != "" {
+		return v, nil
+	}
+	return "", &usageError{cmd: c, msg: govm.T("cli.missing_args", c, govm.T("arg.version"))}
+}
+
+// runConfig implements config list, config get <key> and config set <key> <value>
+func runConfig(ctx *context, args []string) error {
+	cfg := ctx.govm.Config()
+	action := args[0]
+	want := map[string]int{"list": 1, "get": 2, "set": 3}
+	n, ok := want[action]
+	if !ok {
+		return &usageError{cmd: "config", msg: govm.T("config.unknown_action", action)}
+	}
+	if len(args) < n {
+		return &usageError{cmd: "config", msg: govm.T("cli.missing_args", "config "+action, govm.T("arg.config."+action))}
+	}
+	if len(args) > n {
+		return &usageError{cmd: "config", msg: govm.T("cli.too_many_args", "config "+action, strings.Join(args[n:], " "))}
+	}
+
+	switch action {
+	case "list":
+		settings := cfg.Settings()
+		if ctx.flags.json {
+			return writeJSON(ctx, configOutput{Path: cfg.Path(), Settings: settings})
+		}
+		for _, s := range settings {
+			fmt.Fprintf(ctx.out, "%s%s %s\n", pad(s.Key, 26), pad(strconv.Quote(s.Value), 40), govm.CurrentReporter().Sprint(govm.ColorInfo, configSource(s)))
+		}
+	case "get":
+		value, err := cfg.Get(args[1])
+		if err != nil {
+			return err
+		}
+		if ctx.flags.json {
+			return writeJSON(ctx, map[string]string{"key": args[1], "value": value})
+		}
+		fmt.Fprintln(ctx.out, value)
+	case "set":
+		if ctx.flags.json {
+			return &usageError{cmd: "config", msg: govm.T("cli.no_json", "config set")}
+		}
+		if err := govm.SetConfig(ctx.govm.Layout(), args[1], args[2]); err != nil {
+			return err
+		}
+		govm.SuccessT("config.set", args[1], args[2], govm.ConfigPath(ctx.govm.Layout()))
+		for _, s := range cfg.Settings() {
+			if s.Key == args[1] && s.Source == govm.SourceEnv {
+				govm.InfoT("config.env_overrides", s.Env, args[1])
+			}
+		}
+	}
+	return nil
+}
+
+// runVerify checks the given version, the current one or with --all every
+// installed version, and fails when any is damaged
+func runVerify(ctx *context, args []string) error {
+	var results []govm.VerifyResult
+	switch {
+	case verifyAll:
+		if len(args) > 0 {
+			return &usageError{cmd: "verify", msg: govm.T("cli.too_many_args", "verify --all", args[0])}
+		}
+		all, err := ctx.govm.VerifyAll()
+		if err != nil {
+			return err
+		}
+		results = all
+	default:
+		v := ctx.govm.CurrentVersion()
+		if len(args) == 1 {
+			v = args[0]
+		}
+		if v == "" {
+			return &usageError{cmd: "verify", msg: govm.T("cli.missing_args", "verify", govm.T("arg.verify"))}
+		}
+		r, err := ctx.govm.Verify(v)
+		if err != nil {
+			return err
+		}
+		results = []govm.VerifyResult{r}
+	}
+
+	if ctx.flags.json {
+		if err := writeJSON(ctx, verifyOutput{Versions: results}); err != nil {
+			return err
+		}
+	} else {
+		limit := 10
+		if ctx.flags.verbose {
+			limit = -1
+		}
+		govm.PrintVerifyResults(ctx.out, results, limit)
+	}
+	damaged := 0
+	for _, r := range results {
+		if !r.OK() && !r.NoManifest {
+			damaged++
+		}
+	}
+	if damaged > 0 {
+		return errors.New(govm.T("verify.failed", damaged))
+	}
+	return nil
+}
+
+// runUpgradeGo upgrades the installed minor versions to their latest patch,
+// or only shows the plan with --dry-run
+func runUpgradeGo(ctx *context, args []string) error {
+	plan, err := ctx.govm.PlanGoUpgrade(upgradeGoOpts)
+	if err != nil {
+		return err
+	}
+	if ctx.flags.json {
+		if plan == nil {
+			plan = []govm.GoUpgrade{}
+		}
+		if !upgradeGoDryRun {
+			if err := ctx.govm.UpgradeGo(plan); err != nil {
+				return err
+			}
+		}
+		return writeJSON(ctx, upgradeGoOutput{DryRun: upgradeGoDryRun, Upgrades: plan})
+	}
+	govm.PrintGoUpgrades(ctx.out, plan)
+	if upgradeGoDryRun {
+		if len(plan) > 0 {
+			govm.InfoT("upgrade_go.dry_run")
+		}
+		return nil
+	}
+	return ctx.govm.UpgradeGo(plan)
+}
+
+// runAlias implements alias list, set <name> <version>, rm <name> and update [name]
+func runAlias(ctx *context, args []string) error {
+	action := args[0]
+	want := map[string][2]int{"list": {1, 1}, "set": {3, 3}, "rm": {2, 2}, "update": {1, 2}}
+	n, ok := want[action]
+	if !ok {
+		return &usageError{cmd: "alias", msg: govm.T("alias.unknown_action", action)}
+	}
+	if len(args) < n[0] {
+		return &usageError{cmd: "alias", msg: govm.T("cli.missing_args", "alias "+action, govm.T("arg.alias."+action))}
+	}
+	if len(args) > n[1] {
+		return &usageError{cmd: "alias", msg: govm.T("cli.too_many_args", "alias "+action, args[n[1]])}
+	}
+
+	switch action {
+	case "list":
+		aliases, err := ctx.govm.Aliases()
+		if err != nil {
+			return err
+		}
+		if ctx.flags.json {
+			return writeJSON(ctx, aliasOutput{Aliases: aliases})
+		}
+		govm.PrintAliases(ctx.out, aliases)
+	case "set":
+		a, err := ctx.govm.SetAlias(args[1], args[2])
+		if err != nil {
+			return err
+		}
+		if ctx.flags.json {
+			return writeJSON(ctx, a)
+		}
+		govm.SuccessT("alias.set", a.Name, a.Version)
+	case "rm":
+		if ctx.flags.json {
+			return &usageError{cmd: "alias", msg: govm.T("cli.no_json", "alias rm")}
+		}
+		if err := ctx.govm.RemoveAlias(args[1]); err != nil {
+			return err
+		}
+		govm.SuccessT("alias.removed", args[1])
+	case "update":
+		changed, err := ctx.govm.UpdateAliases(args[1:]...)
+		if err != nil {
+			return err
+		}
+		if ctx.flags.json {
+			if changed == nil {
+				changed = []govm.Alias{}
+			}
+			return writeJSON(ctx, aliasOutput{Aliases: changed})
+		}
+		if len(changed) == 0 {
+			govm.InfoT("alias.up_to_date")
+		}
+		for _, a := range changed {
+			govm.SuccessT("alias.updated", a.Name, a.Version, a.Selector)
+		}
+	}
+	return nil
+}
+
+// runImport imports the installation at the argument, or lists the ones
+// found on the machine with --scan
+func runImport(ctx *context, args []string) error {
+	if importScan {
+		if len(args) > 0 {
+			return &usageError{cmd: "import", msg: govm.T("cli.too_many_args", "import --scan", args[0])}
+		}
+		candidates := ctx.govm.ScanImports()
+		if ctx.flags.json {
+			if candidates == nil {
+				candidates = []govm.ImportCandidate{}
+			}
+			return writeJSON(ctx, importScanOutput{Candidates: candidates})
+		}
+		govm.PrintImportCandidates(ctx.out, candidates)
+		return nil
+	}
+	if len(args) == 0 {
+		return &usageError{cmd: "import", msg: govm.T("cli.missing_args", "import", govm.T("arg.import"))}
+	}
+	switch importMode {
+	case govm.ImportCopy, govm.ImportMove, govm.ImportLink:
+	default:
+		return &usageError{cmd: "import", msg: govm.T("import.bad_mode", importMode)}
+	}
+	r, err := ctx.govm.Import(args[0], importMode)
+	if err != nil {
+		return err
+	}
+	if ctx.flags.json {
+		return writeJSON(ctx, r)
+	}
+	govm.SuccessT("import.done", r.Version, r.Source, r.Import)
+	return nil
+}
+
+// runDoctor reports the problems of the setup and fixes what it can with --fix,
+// it fails while errors remain
+func runDoctor(ctx *context, args []string) error {
+	problems := ctx.govm.Doctor(doctorOpts)
+	var fixErr error
+	if doctorFix {
+		fixErr = ctx.govm.FixProblems(problems)
+	}
+	if ctx.flags.json {
+		if problems == nil {
+			problems = []govm.Problem{}
+		}
+		if err := writeJSON(ctx, doctorOutput{Problems: problems}); err != nil {
+			return err
+		}
+	} else {
+		govm.PrintProblems(ctx.out, problems)
+	}
+	if fixErr != nil {
+		return fixErr
+	}
+	remaining := 0
+	for _, p := range problems {
+		if p.Severity == govm.SeverityError && !p.Fixed {
+			remaining++
+		}
+	}
+	if remaining > 0 {
+		return errors.New(govm.T("doctor.failed", remaining))
+	}
+	return nil
+}
+
+// runLsRemote lists the remote versions selected by the filter flags in the
+// format of --format, --json being --format json
+func runLsRemote(ctx *context, args []string) error {
+	switch lsRemoteFormat {
+	case "json":
+		if !ctx.flags.json {
+			// keep status messages out of JSON output like --json does
+			ctx.flags.json = true
+			govm.CurrentReporter().Level = govm.LevelQuiet
+		}
+	case "table", "plain":
+	default:
+		return &usageError{cmd: "ls-remote", msg: govm.T("cli.bad_format", lsRemoteFormat)}
+	}
+	if err := lsRemoteFilter.Validate(); err != nil {
+		return &usageError{cmd: "ls-remote", msg: err.Error()}
+	}
+	groups, err := ctx.govm.FilterRemoteVersions(lsRemoteFilter)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ctx.flags.json:
+		if groups == nil {
+			groups = []govm.VersionGroup{}
+		}
+		return writeJSON(ctx, lsRemoteOutput{Groups: groups})
+	case lsRemoteFormat == "plain":
+		govm.PrintVersionList(ctx.out, groups, lsRemoteInstalled)
+	default:
+		govm.PrintVersionGroups(ctx.out, groups, lsRemoteInstalled)
+	}
+	return nil
+}
+
+// runNotes shows the release history of a version or a range of versions
+func runNotes(ctx *context, args []string) error {
+	notes, err := ctx.govm.ReleaseNotes(args[0], notesOpts)
+	if err != nil {
+		return err
+	}
+	if ctx.flags.json {
+		return writeJSON(ctx, notesOutput{Notes: notes})
+	}
+	govm.PrintReleaseNotes(ctx.out, notes)
+	return nil
+}
+
+// runAudit reports the installed versions past end of support or with known
+// vulnerabilities and fails when there are any
+func runAudit(ctx *context, args []string) error {
+	report, err := ctx.govm.Audit(auditOpts)
+	if err != nil {
+		return err
+	}
+	if ctx.flags.json {
+		if err := writeJSON(ctx, auditOutput{report}); err != nil {
+			return err
+		}
+	} else {
+		govm.PrintAuditReport(ctx.out, report)
+	}
+	affected := 0
+	for _, v := range report.Versions {
+		if v.Affected() {
+			affected++
+		}
+	}
+	if affected > 0 {
+		return errors.New(govm.T("audit.failed", affected))
+	}
+	return nil
+}
+
+// runExplain shows the decision chain of the version for the working directory
+func runExplain(ctx *context) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	e := ctx.govm.Explain(dir)
+	if ctx.flags.json {
+		return writeJSON(ctx, e)
+	}
+	govm.PrintExplanation(ctx.out, e)
+	return nil
+}
+
+// runWhich prints the go binary of the version for the working directory,
+// what keeps it from running goes to stderr
+func runWhich(ctx *context, args []string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	e := ctx.govm.Explain(dir)
+	if ctx.flags.json {
+		return writeJSON(ctx, e)
+	}
+	switch {
+	case e.Selector == "":
+		return errors.New(govm.T("which.none"))
+	case e.Version == "":
+		return errors.New(govm.T("which.no_match", e.Selector, e.Selector))
+	case !e.Installed:
+		return errors.New(govm.T("exec.not_installed", e.Version))
+	}
+	fmt.Fprintln(ctx.out, e.Binary)
+	govm.PrintExplanationNotes(os.Stderr, e)
+	return nil
+}
+
+// runExec runs a command with a version without switching to it,
+// the exit status of the command becomes the one of govm
+func runExec(ctx *context, args []string) error {
+	cmd, err := ctx.govm.ExecCommand(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, ctx.out, os.Stderr
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); errors.As(err, &exitErr) {
+		return exitStatus(exitErr.ExitCode())
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// runPrune shows the versions the policy removes, and removes them with --yes
+func runPrune(ctx *context, args []string) error {
+	policy := pruneOpts
+	policy.KeepPinned = make([]string, 0, len(pruneOpts.KeepPinned))
+	for _, dir := range pruneOpts.KeepPinned {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		policy.KeepPinned = append(policy.KeepPinned, abs)
+	}
+	decisions, err := ctx.govm.PlanPrune(policy, time.Now())
+	if err != nil {
+		return &usageError{cmd: "prune", msg: err.Error()}
+	}
+
+	if ctx.flags.json {
+		if !pruneYes {
+			return writeJSON(ctx, pruneOutput{Versions: decisions})
+		}
+		if err := ctx.govm.Prune(decisions); err != nil {
+			return err
+		}
+		return writeJSON(ctx, pruneOutput{Removed: true, Versions: decisions})
+	}
+	govm.PrintPruneDecisions(ctx.out, decisions)
+	if !pruneYes {
+		for _, d := range decisions {
+			if d.Remove {
+				govm.InfoT("prune.dry_run")
+				break
+			}
+		}
+		return nil
+	}
+	return ctx.govm.Prune(decisions)
+}
+
+// runStore implements store stats and store gc
+func runStore(ctx *context, args []string) error {
+	switch args[0] {
+	case "stats":
+		stats, err := ctx.govm.StoreStats()
+		if err != nil {
+			return err
+		}
+		if ctx.flags.json {
+			return writeJSON(ctx, stats)
+		}
+		govm.PrintStoreStats(ctx.out, stats)
+	case "gc":
+		if ctx.flags.json {
+			return &usageError{cmd: "store", msg: govm.T("cli.no_json", "store gc")}
+		}
+		removed, freed, err := ctx.govm.CollectGarbage()
+		if err != nil {
+			return err
+		}
+		govm.InfoT("store.gc", removed, govm.FormatBytes(freed))
+	default:
+		return &usageError{cmd: "store", msg: govm.T("store.unknown_action", args[0])}
+	}
+	return nil
+}
+
+// runMigrateHome moves the install to the given directory, or to the XDG dirs
+func runMigrateHome(ctx *context, args []string) error {
+	var dst govm.Layout
+	if len(args) == 1 {
+		dir, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		dst = govm.HomeLayout(dir)
+	} else {
+		xdg, err := govm.XDGLayout()
+		if err != nil {
+			return err
+		}
+		dst = xdg
+	}
+
+	if err := ctx.govm.MigrateHome(dst); err != nil {
+		return err
+	}
+	govm.SuccessT("migrate.done", dst.Home)
+	if dst.XDG {
+		if os.Getenv("GOVM_HOME") != "" {
+			govm.InfoT("migrate.unset_home")
+		}
+	} else {
+		govm.InfoT("migrate.set_home", dst.Home)
+	}
+	govm.InfoT("migrate.path_hint", filepath.Join(dst.Home, "current", "bin"), filepath.Join(dst.Home, "bin"))
+	return nil
+}
+
+// configSource describes where a config value came from
+func configSource(s govm.ConfigSetting) string {
+	switch s.Source {
+	case govm.SourceEnv:
+		return govm.T("config.source.env", s.Env)
+	case govm.SourceFile:
+		return govm.T("config.source.file")
+	}
+	return govm.T("config.source.default")
+}
+
+// configOutput is the --json schema of config list
+type configOutput struct {
+	Path     string               `json:"path"`
+	Settings []govm.ConfigSetting `json:"settings"`
+}
+
+// listOutput is the --json schema of list
+type listOutput struct {
+	Current  string                  `json:"current"`
+	Versions []govm.InstalledVersion `json:"versions"`
+}
+
+// verifyOutput is the --json schema of verify
+type verifyOutput struct {
+	Versions []govm.VerifyResult `json:"versions"`
+}
+
+// upgradeGoOutput is the --json schema of upgrade-go
+type upgradeGoOutput struct {
+	DryRun   bool             `json:"dry_run"`
+	Upgrades []govm.GoUpgrade `json:"upgrades"`
+}
+
+// aliasOutput is the --json schema of alias list and alias update
+type aliasOutput struct {
+	Aliases []govm.Alias `json:"aliases"`
+}
+
+// importScanOutput is the --json schema of import --scan
+type importScanOutput struct {
+	Candidates []govm.ImportCandidate `json:"candidates"`
+}
+
+// doctorOutput is the --json schema of doctor
+type doctorOutput struct {
+	Problems []govm.Problem `json:"problems"`
+}
+
+// notesOutput is the --json schema of notes
+type notesOutput struct {
+	Notes []govm.ReleaseNote `json:"notes"`
+}
+
+// auditOutput is the --json schema of audit
+type auditOutput struct {
+	govm.AuditReport
+}
+
+// pruneOutput is the --json schema of prune, Removed is set with --yes
+type pruneOutput struct {
+	Removed  bool                 `json:"removed"`
+	Versions []govm.PruneDecision `json:"versions"`
+}
+
+// stringList collects the values of a flag given several times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// ageValue is a duration flag that also accepts days, e.g. 30d
+type ageValue time.Duration
+
+func (a *ageValue) String() string {
+	return time.Duration(*a).String()
+}
+
+func (a *ageValue) Set(s string) error {
+	d, err := govm.ParseAge(s)
+	*a = ageValue(d)
+	return err
+}
+
+// lsRemoteOutput is the --json schema of ls-remote
+type lsRemoteOutput struct {
+	Groups []govm.VersionGroup `json:"groups"`
+}
+
+// currentOutput is the --json schema of current
+type currentOutput struct {
+	Version string `json:"version"`
+	Path    string `json:"path,omitempty"`
+}
+
+func writeJSON(ctx *context, v interface{}) error {
+	enc := json.NewEncoder(ctx.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,418 @@
+package govm
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+const configFile = "config.toml"
+
+// Sources of a config value
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// Color modes of ui.color
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Config is the content of config.toml. Every setting can be overridden by
+// the environment variables in its env tag, the first one that is set wins.
+type Config struct {
+	Mirror   MirrorConfig   `toml:"mirror"`
+	Cache    CacheConfig    `toml:"cache"`
+	Network  NetworkConfig  `toml:"network"`
+	Defaults DefaultsConfig `toml:"defaults"`
+	UI       UIConfig       `toml:"ui"`
+	// Parallelism bounds the number of concurrent file operations
+	Parallelism int            `toml:"parallelism" env:"GOVM_PARALLELISM"`
+	Hooks       HooksConfig    `toml:"hooks"`
+	Update      UpdateConfig   `toml:"update"`
+	System      SystemConfig   `toml:"system"`
+	Store       StoreConfig    `toml:"store"`
+	Projects    ProjectsConfig `toml:"projects"`
+
+	path    string
+	sources map[string]ConfigSetting
+}
+
+// MirrorConfig selects where Go is downloaded from
+type MirrorConfig struct {
+	// Registry is the base URL Go archives are downloaded from
+	Registry string `toml:"registry" env:"GOVM_REGISTRY,GOBREW_REGISTRY"`
+	// Fallbacks are tried in order when a download from Registry fails
+	Fallbacks []string `toml:"fallbacks" env:"GOVM_MIRROR_FALLBACKS"`
+	// TagsAPI lists the published Go versions
+	TagsAPI string `toml:"tags_api" env:"GOVM_TAGS_API"`
+}
+
+// CacheConfig controls what govm keeps between runs
+type CacheConfig struct {
+	// KeepDownloads keeps the downloaded archives for reinstalls
+	KeepDownloads bool `toml:"keep_downloads" env:"GOVM_CACHE_KEEP_DOWNLOADS"`
+	// RemoteTTL is how long the list of remote versions is reused, 0 disables the cache
+	RemoteTTL string `toml:"remote_ttl" env:"GOVM_CACHE_REMOTE_TTL"`
+}
+
+// NetworkConfig for every request govm makes
+type NetworkConfig struct {
+	// Proxy overrides HTTPS_PROXY and HTTP_PROXY
+	Proxy string `toml:"proxy" env:"GOVM_PROXY"`
+}
+
+// DefaultsConfig holds the selectors used when a command is given no version
+type DefaultsConfig struct {
+	Version string `toml:"version" env:"GOVM_DEFAULT_VERSION"`
+}
+
+// UIConfig controls how messages are printed
+type UIConfig struct {
+	// Color is ColorAuto, ColorAlways or ColorNever
+	Color string `toml:"color" env:"GOVM_COLOR"`
+	// Locale is en or zh-CN, detected from the environment when empty
+	Locale string `toml:"locale" env:"GOVM_LANG"`
+}
+
+// HooksConfig are shell commands run around installs and switches,
+// with GOVM_VERSION and GOVM_HOME in their environment
+type HooksConfig struct {
+	PreInstall  string `toml:"pre_install" env:"GOVM_HOOK_PRE_INSTALL"`
+	PostInstall string `toml:"post_install" env:"GOVM_HOOK_POST_INSTALL"`
+	PostUse     string `toml:"post_use" env:"GOVM_HOOK_POST_USE"`
+}
+
+// UpdateConfig controls the update notification and self-update
+type UpdateConfig struct {
+	// Channel is ChannelStable or ChannelPrerelease
+	Channel string `toml:"channel" env:"GOVM_UPDATE_CHANNEL"`
+	// Check turns the daily update notification on, e.g. off in CI
+	Check bool `toml:"check" env:"GOVM_UPDATE_CHECK"`
+}
+
+// SystemConfig locates the store of toolchains shared by every user
+type SystemConfig struct {
+	Store string `toml:"store" env:"GOVM_SYSTEM_STORE"`
+}
+
+// StoreConfig controls the content-addressed store of extracted files
+type StoreConfig struct {
+	// Dedupe hard-links identical files of the installed versions to one blob
+	Dedupe bool `toml:"dedupe" env:"GOVM_STORE_DEDUPE"`
+}
+
+// ProjectsConfig locates the projects whose pinned versions list --long shows
+type ProjectsConfig struct {
+	// Dirs are searched for .go-version files and go.mod toolchain lines
+	Dirs []string `toml:"dirs" env:"GOVM_PROJECT_DIRS"`
+}
+
+// ConfigSetting is a single config value and where it came from
+type ConfigSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// Env is the variable the value came from, or else the one overriding it
+	Env string `json:"env"`
+}
+
+// DefaultConfig is used for the settings missing from config.toml
+func DefaultConfig() Config {
+	return Config{
+		Mirror: MirrorConfig{
+			Registry: defaultRegistryPath,
+			TagsAPI:  defaultTagsApi,
+		},
+		Cache:       CacheConfig{RemoteTTL: "1h"},
+		UI:          UIConfig{Color: ColorAuto},
+		Parallelism: 4,
+		Update:      UpdateConfig{Channel: ChannelStable, Check: true},
+		System:      SystemConfig{Store: defaultSystemStore()},
+	}
+}
+
+// ConfigPath is GOVM_CONFIG or config.toml in the config dir of l
+func ConfigPath(l Layout) string {
+	if p := os.Getenv("GOVM_CONFIG"); p != "" {
+		return p
+	}
+	return filepath.Join(l.Config, configFile)
+}
+
+// LoadConfig reads the config of the layout and applies the environment overrides,
+// a missing config file is not an error
+func LoadConfig(l Layout) (Config, error) {
+	cfg := DefaultConfig()
+	cfg.path = ConfigPath(l)
+	cfg.sources = make(map[string]ConfigSetting)
+
+	md, err := toml.DecodeFile(cfg.path, &cfg)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cfg, errors.New(T("config.parse_failed", cfg.path, err))
+	}
+	traceFS("read", cfg.path, err)
+	if err == nil {
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return cfg, errors.New(T("config.unknown_key_in_file", cfg.path, undecoded[0].String()))
+		}
+	}
+
+	v := reflect.ValueOf(&cfg).Elem()
+	for _, f := range configFields() {
+		setting := ConfigSetting{Key: f.key, Source: SourceDefault, Env: f.env[0]}
+		if err == nil && md.IsDefined(strings.Split(f.key, ".")...) {
+			setting.Source = SourceFile
+		}
+		for _, name := range f.env {
+			value := os.Getenv(name)
+			if value == "" {
+				continue
+			}
+			if err := setConfigField(v.FieldByIndex(f.index), f.key, value); err != nil {
+				return cfg, errors.New(T("config.bad_env", name, err))
+			}
+			setting.Source = SourceEnv
+			setting.Env = name
+			break
+		}
+		cfg.sources[f.key] = setting
+	}
+	return cfg, cfg.validate()
+}
+
+// Path of the config file
+func (c Config) Path() string {
+	return c.path
+}
+
+// Get returns the value of a key such as mirror.registry
+func (c Config) Get(key string) (string, error) {
+	f, ok := lookupConfigField(key)
+	if !ok {
+		return "", errors.New(T("config.unknown_key", key))
+	}
+	return formatConfigField(reflect.ValueOf(c).FieldByIndex(f.index)), nil
+}
+
+// Settings lists every key with its value and source, in the order of Config
+func (c Config) Settings() []ConfigSetting {
+	fields := configFields()
+	settings := make([]ConfigSetting, 0, len(fields))
+	for _, f := range fields {
+		s, ok := c.sources[f.key]
+		if !ok {
+			s = ConfigSetting{Key: f.key, Source: SourceDefault, Env: f.env[0]}
+		}
+		s.Value = formatConfigField(reflect.ValueOf(c).FieldByIndex(f.index))
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+// RemoteTTL is Cache.RemoteTTL parsed, validate makes sure it parses
+func (c Config) RemoteTTL() time.Duration {
+	d, _ := time.ParseDuration(c.Cache.RemoteTTL)
+	return d
+}
+
+// SetConfig writes key = value to the config file of the layout,
+// keeping the other keys of the file
+func SetConfig(l Layout, key, value string) error {
+	f, ok := lookupConfigField(key)
+	if !ok {
+		return errors.New(T("config.unknown_key", key))
+	}
+	path := ConfigPath(l)
+
+	// validate the value together with the rest of the file
+	cfg := DefaultConfig()
+	raw := make(map[string]interface{})
+	if _, err := toml.DecodeFile(path, &raw); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.New(T("config.parse_failed", path, err))
+	}
+	if _, err := toml.DecodeFile(path, &cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.New(T("config.parse_failed", path, err))
+	}
+	field := reflect.ValueOf(&cfg).Elem().FieldByIndex(f.index)
+	if err := setConfigField(field, key, value); err != nil {
+		return err
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	table := raw
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		sub, ok := table[part].(map[string]interface{})
+		if !ok {
+			sub = make(map[string]interface{})
+			table[part] = sub
+		}
+		table = sub
+	}
+	if field.Kind() == reflect.Slice && field.Len() == 0 {
+		delete(table, parts[len(parts)-1])
+	} else {
+		table[parts[len(parts)-1]] = field.Interface()
+	}
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(raw); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	err := os.WriteFile(path, buf.Bytes(), 0644)
+	traceFS("write", path, err)
+	return err
+}
+
+func (c Config) validate() error {
+	choices := []struct {
+		key, value string
+		allowed    []string
+	}{
+		{"ui.color", c.UI.Color, []string{ColorAuto, ColorAlways, ColorNever}},
+		{"update.channel", c.Update.Channel, []string{ChannelStable, ChannelPrerelease}},
+	}
+	for _, choice := range choices {
+		if !Find(choice.allowed, choice.value) {
+			return errors.New(T("config.bad_choice", choice.key, strings.Join(choice.allowed, ", "), choice.value))
+		}
+	}
+	if d, err := time.ParseDuration(c.Cache.RemoteTTL); err != nil || d < 0 {
+		return errors.New(T("config.bad_duration", "cache.remote_ttl", c.Cache.RemoteTTL))
+	}
+	if !filepath.IsAbs(c.System.Store) {
+		return errors.New(T("config.bad_path", "system.store", c.System.Store))
+	}
+	for _, dir := range c.Projects.Dirs {
+		if !filepath.IsAbs(dir) {
+			return errors.New(T("config.bad_path", "projects.dirs", dir))
+		}
+	}
+	if c.Defaults.Version != "" {
+		if _, err := ParseSelector(c.Defaults.Version); err != nil {
+			return errors.New(T("config.bad_selector", "defaults.version", err))
+		}
+	}
+	if c.Parallelism < 1 {
+		return errors.New(T("config.bad_parallelism", c.Parallelism))
+	}
+	urls := map[string][]string{
+		"mirror.registry":  {c.Mirror.Registry},
+		"mirror.fallbacks": c.Mirror.Fallbacks,
+		"mirror.tags_api":  {c.Mirror.TagsAPI},
+	}
+	if c.Network.Proxy != "" {
+		urls["network.proxy"] = []string{c.Network.Proxy}
+	}
+	for key, values := range urls {
+		for _, value := range values {
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+				return errors.New(T("config.bad_url", key, value))
+			}
+		}
+	}
+	return nil
+}
+
+// configField is a leaf of Config, key is its dotted toml path
+type configField struct {
+	key   string
+	env   []string
+	index []int
+}
+
+func configFields() []configField {
+	var fields []configField
+	var walk func(t reflect.Type, prefix string, index []int)
+	walk = func(t reflect.Type, prefix string, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name := sf.Tag.Get("toml")
+			if name == "" {
+				continue
+			}
+			fieldIndex := append(append([]int{}, index...), i)
+			if sf.Type.Kind() == reflect.Struct {
+				walk(sf.Type, prefix+name+".", fieldIndex)
+				continue
+			}
+			fields = append(fields, configField{
+				key:   prefix + name,
+				env:   strings.Split(sf.Tag.Get("env"), ","),
+				index: fieldIndex,
+			})
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "", nil)
+	return fields
+}
+
+func lookupConfigField(key string) (configField, bool) {
+	for _, f := range configFields() {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return configField{}, false
+}
+
+func formatConfigField(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int:
+		return strconv.Itoa(int(v.Int()))
+	case reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return v.String()
+}
+
+// setConfigField parses value into v, lists are comma separated
+func setConfigField(v reflect.Value, key, value string) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New(T("config.bad_bool", key, value))
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New(T("config.bad_int", key, value))
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		v.SetString(value)
+	}
+	return nil
+}
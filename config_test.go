@@ -0,0 +1,121 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	home := t.TempDir()
+	data := `
+parallelism = 2
+
+[mirror]
+registry = "https://mirror.example.com/go/"
+fallbacks = ["https://golang.org/dl/"]
+
+[update]
+check = false
+`
+	if err := os.WriteFile(filepath.Join(home, configFile), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_REGISTRY", "")
+	t.Setenv("GOBREW_REGISTRY", "https://legacy.example.com/")
+	t.Setenv("GOVM_UPDATE_CHANNEL", "prerelease")
+
+	cfg, err := LoadConfig(HomeLayout(home))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Parallelism != 2 || cfg.Update.Check || len(cfg.Mirror.Fallbacks) != 1 {
+		t.Errorf("file not applied: %+v", cfg)
+	}
+	if cfg.Mirror.Registry != "https://legacy.example.com/" || cfg.Update.Channel != ChannelPrerelease {
+		t.Errorf("environment not applied: %+v", cfg)
+	}
+	if cfg.Cache.RemoteTTL != "1h" {
+		t.Errorf("default not kept: %+v", cfg)
+	}
+
+	sources := make(map[string]ConfigSetting)
+	for _, s := range cfg.Settings() {
+		sources[s.Key] = s
+	}
+	for key, want := range map[string]string{
+		"parallelism":      SourceFile,
+		"update.check":     SourceFile,
+		"mirror.registry":  SourceEnv,
+		"update.channel":   SourceEnv,
+		"cache.remote_ttl": SourceDefault,
+	} {
+		if got := sources[key].Source; got != want {
+			t.Errorf("source of %s = %s, want %s", key, got, want)
+		}
+	}
+	if sources["mirror.registry"].Env != "GOBREW_REGISTRY" {
+		t.Errorf("got %+v", sources["mirror.registry"])
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	cases := map[string]string{
+		"unknown key":   "[mirror]\nregistri = \"https://golang.org/dl/\"\n",
+		"bad color":     "[ui]\ncolor = \"sometimes\"\n",
+		"bad duration":  "[cache]\nremote_ttl = \"soon\"\n",
+		"bad url":       "[network]\nproxy = \"localhost\"\n",
+		"bad toml":      "parallelism = \n",
+		"bad parallels": "parallelism = 0\n",
+		"bad selector":  "[defaults]\nversion = \"~latest\"\n",
+	}
+	for name, data := range cases {
+		home := t.TempDir()
+		if err := os.WriteFile(filepath.Join(home, configFile), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(HomeLayout(home)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+
+	t.Setenv("GOVM_UPDATE_CHECK", "maybe")
+	if _, err := LoadConfig(HomeLayout(t.TempDir())); err == nil || !strings.Contains(err.Error(), "GOVM_UPDATE_CHECK") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	path := filepath.Join(home, configFile)
+	if err := os.WriteFile(path, []byte("[hooks]\npost_use = \"go version\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetConfig(HomeLayout(home), "mirror.fallbacks", "https://a.example.com/, https://b.example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfig(HomeLayout(home), "parallelism", "8"); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][2]string{{"parallelism", "many"}, {"ui.color", "pink"}, {"no.such", "x"}} {
+		if err := SetConfig(HomeLayout(home), bad[0], bad[1]); err == nil {
+			t.Errorf("set %s = %s: no error", bad[0], bad[1])
+		}
+	}
+
+	cfg, err := LoadConfig(HomeLayout(home))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Hooks.PostUse != "go version" || cfg.Parallelism != 8 {
+		t.Errorf("got %+v", cfg)
+	}
+	if got, _ := cfg.Get("mirror.fallbacks"); got != "https://a.example.com/,https://b.example.com/" {
+		t.Errorf("got %q", got)
+	}
+}
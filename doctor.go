@@ -0,0 +1,307 @@
+package govm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// The checks of Doctor
+const (
+	CheckPath          = "path"
+	CheckSystemGo      = "system_go"
+	CheckGoroot        = "goroot"
+	CheckCurrentLink   = "current_link"
+	CheckHalfInstalled = "half_installed"
+	CheckStaleStaging  = "stale_staging"
+	CheckStaleLock     = "stale_lock"
+	CheckRegistry      = "registry"
+	CheckGobrew        = "gobrew"
+)
+
+// Problem severities, only errors fail doctor
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// systemGoPaths are where Go installs outside govm usually live
+var systemGoPaths = func() []string {
+	if runtime.GOOS == "windows" {
+		return []string{`C:\Program Files\Go\bin\go.exe`, `C:\Go\bin\go.exe`}
+	}
+	return []string{"/usr/local/go/bin/go", "/usr/lib/go/bin/go", "/usr/lib/go-*/bin/go", "/usr/bin/go", "/opt/homebrew/bin/go", "/snap/bin/go"}
+}()
+
+// Problem is something doctor found wrong with the setup
+type Problem struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Path     string `json:"path,omitempty"`
+	// Message says what is wrong and Suggestion how to fix it
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+	// Fixable problems are fixed by FixProblems
+	Fixable bool `json:"fixable"`
+	Fixed   bool `json:"fixed"`
+
+	fix func() error
+}
+
+// DoctorOptions selects the optional checks
+type DoctorOptions struct {
+	// Online checks that the registry answers
+	Online bool
+}
+
+// Doctor checks PATH, Go installs outside govm, GOROOT, the current link,
+// the versions directory and leftovers of gobrew
+func (g *GoVM) Doctor(opts DoctorOptions) []Problem {
+	var problems []Problem
+	for _, check := range []func() []Problem{
+		g.checkPath,
+		g.checkSystemGo,
+		g.checkGoroot,
+		g.checkCurrentLink,
+		g.checkVersionDirs,
+		g.checkGobrew,
+	} {
+		problems = append(problems, check()...)
+	}
+	if opts.Online {
+		problems = append(problems, g.checkRegistry()...)
+	}
+	return problems
+}
+
+// FixProblems fixes the fixable problems and marks them fixed, it stops
+// at the first failure
+func (g *GoVM) FixProblems(problems []Problem) error {
+	for i := range problems {
+		p := &problems[i]
+		if !p.Fixable || p.Fixed {
+			continue
+		}
+		if err := p.fix(); err != nil {
+			return err
+		}
+		p.Fixed = true
+	}
+	return nil
+}
+
+func (g *GoVM) checkPath() []Problem {
+	var problems []Problem
+	_, onPath, shadowing := g.lookPathGo()
+	if !onPath {
+		problems = append(problems, Problem{
+			Check: CheckPath, Severity: SeverityError, Path: g.currentBinDir,
+			Message:    T("doctor.path.missing", g.currentBinDir),
+			Suggestion: T("doctor.path.missing_fix", g.currentBinDir),
+		})
+	}
+	for _, shadow := range shadowing {
+		problems = append(problems, Problem{
+			Check: CheckPath, Severity: SeverityError, Path: shadow,
+			Message:    T("doctor.path.shadowed", shadow, g.currentBinDir),
+			Suggestion: T("doctor.path.shadowed_fix", g.currentBinDir, filepath.Dir(shadow)),
+		})
+	}
+
+	// the govm binary only lives in bin with the install script
+	govm := filepath.Join(g.binDir, "govm")
+	if runtime.GOOS == "windows" {
+		govm += ".exe"
+	}
+	if _, err := os.Stat(govm); err == nil {
+		found := false
+		for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+			if dir != "" && sameDir(dir, g.binDir) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, Problem{
+				Check: CheckPath, Severity: SeverityWarning, Path: g.binDir,
+				Message:    T("doctor.path.missing", g.binDir),
+				Suggestion: T("doctor.path.missing_fix", g.binDir),
+			})
+		}
+	}
+	return problems
+}
+
+func (g *GoVM) checkSystemGo() []Problem {
+	var problems []Problem
+	seen := make(map[string]bool)
+	for _, pattern := range systemGoPaths {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			// distro packages link /usr/bin/go into their own tree, which is
+			// one install, and a link into govm is no conflict at all
+			real, err := filepath.EvalSymlinks(path)
+			if err != nil || seen[real] || strings.HasPrefix(real, g.installDir+string(filepath.Separator)) {
+				continue
+			}
+			seen[real] = true
+			problems = append(problems, Problem{
+				Check: CheckSystemGo, Severity: SeverityWarning, Path: path,
+				Message:    T("doctor.system_go", path),
+				Suggestion: T("doctor.system_go_fix", filepath.Dir(filepath.Dir(real)), filepath.Dir(path)),
+			})
+		}
+	}
+	return problems
+}
+
+func (g *GoVM) checkGoroot() []Problem {
+	goroot := os.Getenv("GOROOT")
+	if goroot == "" || sameDir(goroot, g.currentGoDir) {
+		return nil
+	}
+	return []Problem{{
+		Check: CheckGoroot, Severity: SeverityError, Path: goroot,
+		Message:    T("doctor.goroot", goroot),
+		Suggestion: T("doctor.goroot_fix"),
+	}}
+}
+
+func (g *GoVM) checkCurrentLink() []Problem {
+	var problems []Problem
+	for _, link := range []string{g.currentBinDir, g.currentGoDir} {
+		info, err := os.Lstat(link)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		if _, err := os.Stat(link); err == nil {
+			continue
+		}
+		target, _ := os.Readlink(link)
+		link := link
+		problems = append(problems, Problem{
+			Check: CheckCurrentLink, Severity: SeverityError, Path: link,
+			Message:    T("doctor.current_link", link, target),
+			Suggestion: T("doctor.current_link_fix"),
+			Fixable:    true,
+			fix: func() error {
+				err := os.Remove(link)
+				traceFS("remove", link, err)
+				return err
+			},
+		})
+	}
+	return problems
+}
+
+// checkVersionDirs finds versions without a go command and the staging
+// dirs and locks of killed installs and repairs
+func (g *GoVM) checkVersionDirs() []Problem {
+	entries, err := os.ReadDir(g.versionsDir)
+	if err != nil {
+		return nil
+	}
+	var problems []Problem
+	for _, entry := range entries {
+		path := filepath.Join(g.versionsDir, entry.Name())
+		switch {
+		case strings.HasPrefix(entry.Name(), stagingPrefix) && stagingAbandoned(entry.Name(), path),
+			strings.HasPrefix(entry.Name(), repairPrefix) && !g.versionLocked(strings.TrimPrefix(entry.Name(), repairPrefix)):
+			problems = append(problems, Problem{
+				Check: CheckStaleStaging, Severity: SeverityWarning, Path: path,
+				Message:    T("doctor.stale_staging", path),
+				Suggestion: T("doctor.stale_staging_fix"),
+				Fixable:    true,
+				fix: func() error {
+					g.cleanStaleStaging()
+					return nil
+				},
+			})
+		case strings.HasPrefix(entry.Name(), lockPrefix):
+			pid, stale, err := staleLock(path)
+			if err != nil || !stale {
+				continue
+			}
+			problems = append(problems, Problem{
+				Check: CheckStaleLock, Severity: SeverityWarning, Path: path,
+				Message:    T("doctor.stale_lock", path, pid),
+				Suggestion: T("doctor.stale_lock_fix"),
+				Fixable:    true,
+				fix: func() error {
+					err := os.Remove(path)
+					traceFS("remove", path, err)
+					return err
+				},
+			})
+		case strings.HasPrefix(entry.Name(), "."):
+		case !entry.IsDir():
+		default:
+			if _, err := os.Stat(filepath.Join(path, "go", "bin", goBinary())); err == nil {
+				continue
+			}
+			problems = append(problems, Problem{
+				Check: CheckHalfInstalled, Severity: SeverityError, Path: path,
+				Message:    T("doctor.half_installed", entry.Name(), path),
+				Suggestion: T("doctor.half_installed_fix", entry.Name()),
+				Fixable:    true,
+				fix: func() error {
+					err := os.RemoveAll(path)
+					traceFS("remove", path, err)
+					return err
+				},
+			})
+		}
+	}
+	return problems
+}
+
+func (g *GoVM) checkGobrew() []Problem {
+	var problems []Problem
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if strings.Contains(dir, ".gobrew") {
+			problems = append(problems, Problem{
+				Check: CheckGobrew, Severity: SeverityWarning, Path: dir,
+				Message:    T("doctor.gobrew_path", dir),
+				Suggestion: T("doctor.gobrew_path_fix"),
+			})
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dir := filepath.Join(home, ".gobrew")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() && !sameDir(dir, g.installDir) {
+			problems = append(problems, Problem{
+				Check: CheckGobrew, Severity: SeverityWarning, Path: dir,
+				Message:    T("doctor.gobrew_dir", dir),
+				Suggestion: T("doctor.gobrew_dir_fix", dir),
+			})
+		}
+	}
+	return problems
+}
+
+func (g *GoVM) checkRegistry() []Problem {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, "HEAD", g.registry, nil)
+	if err == nil {
+		request.Header.Set("User-Agent", "govm")
+		var response *http.Response
+		if response, err = httpClient.Do(request); err == nil {
+			_ = response.Body.Close()
+			if response.StatusCode < 500 {
+				return nil
+			}
+			err = errors.New(T("download.bad_status", response.Status, g.registry))
+		}
+	}
+	return []Problem{{
+		Check: CheckRegistry, Severity: SeverityError, Path: g.registry,
+		Message:    T("doctor.registry", g.registry, err),
+		Suggestion: T("doctor.registry_fix"),
+	}}
+}
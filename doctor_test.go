@@ -0,0 +1,106 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	home, user, system := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	t.Setenv("HOME", user)
+	t.Setenv("GOROOT", "/opt/go")
+	t.Setenv("PATH", filepath.Join(user, ".gobrew", "bin"))
+	systemGo := filepath.Join(system, "go", "bin", "go")
+	defer func(paths []string) { systemGoPaths = paths }(systemGoPaths)
+	// a distro links its go into its own tree, that is one install
+	systemLink := filepath.Join(system, "bin", "go")
+	systemGoPaths = []string{systemGo, systemLink}
+
+	for _, dir := range []string{
+		filepath.Join(home, "versions", "1.21.0", "go", "bin"),
+		filepath.Join(home, "versions", "1.20.1"),
+		filepath.Join(home, "versions", stagingPrefix+"999999999-1"),
+		// the backup of a killed repair and one of a repair holding its lock
+		filepath.Join(home, "versions", repairPrefix+"1.22.2", "go", "bin"),
+		filepath.Join(home, "versions", repairPrefix+"1.22.1"),
+		filepath.Join(home, "current"),
+		filepath.Join(user, ".gobrew"),
+		filepath.Dir(systemGo),
+		filepath.Dir(systemLink),
+	} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{filepath.Join(home, "versions", "1.21.0", "go", "bin", goBinary()), filepath.Join(home, "versions", repairPrefix+"1.22.2", "go", "bin", goBinary()), systemGo} {
+		if err := os.WriteFile(f, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(systemGo, systemLink); err != nil {
+		t.Fatal(err)
+	}
+	// a lock of a gone process and one of this process
+	staleLock := filepath.Join(home, "versions", lockPrefix+"1.22.0")
+	heldLock := filepath.Join(home, "versions", lockPrefix+"1.22.1")
+	for path, pid := range map[string]int{staleLock: 999999999, heldLock: os.Getpid()} {
+		if err := os.WriteFile(path, []byte(strconv.Itoa(pid)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(home, "versions", "1.19.0", "go", "bin"), g.currentBinDir); err != nil {
+		t.Fatal(err)
+	}
+
+	problems := g.Doctor(DoctorOptions{})
+	var checks []string
+	for _, p := range problems {
+		checks = append(checks, p.Check)
+		if p.Message == "" || p.Suggestion == "" {
+			t.Errorf("%s: empty message or suggestion %+v", p.Check, p)
+		}
+	}
+	sort.Strings(checks)
+	want := []string{CheckCurrentLink, CheckGobrew, CheckGobrew, CheckGoroot, CheckHalfInstalled, CheckPath, CheckStaleLock, CheckStaleStaging, CheckStaleStaging, CheckSystemGo}
+	if len(checks) != len(want) {
+		t.Fatalf("checks %v, want %v", checks, want)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Fatalf("checks %v, want %v", checks, want)
+		}
+	}
+
+	if err := g.FixProblems(problems); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		if p.Fixed != p.Fixable {
+			t.Errorf("%s fixed %v, fixable %v", p.Check, p.Fixed, p.Fixable)
+		}
+	}
+	for _, path := range []string{g.currentBinDir, filepath.Join(home, "versions", "1.20.1"), filepath.Join(home, "versions", stagingPrefix+"999999999-1"), staleLock} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", path, err)
+		}
+	}
+	for _, path := range []string{filepath.Join(home, "versions", "1.21.0"), filepath.Join(home, "versions", "1.22.2"), filepath.Join(home, "versions", repairPrefix+"1.22.1"), heldLock} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s removed: %v", path, err)
+		}
+	}
+	for _, p := range g.Doctor(DoctorOptions{}) {
+		if p.Fixable {
+			t.Errorf("left after fixing: %+v", p)
+		}
+	}
+}
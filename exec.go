@@ -0,0 +1,46 @@
+package govm
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ExecCommand prepares args to run with the installed version the selector
+// names first on PATH and GOROOT pointing to it, without switching the
+// current version. The version is recorded as used.
+func (g *GoVM) ExecCommand(version string, args []string) (*exec.Cmd, error) {
+	version, err := g.resolveInstalled(version)
+	if err != nil {
+		return nil, err
+	}
+	dir, _, ok := g.findVersion(version)
+	if !ok {
+		return nil, errors.New(T("exec.not_installed", version))
+	}
+	goroot := filepath.Join(dir, "go")
+	bin := filepath.Join(goroot, "bin")
+
+	path, err := exec.LookPath(args[0])
+	if candidate := filepath.Join(bin, args[0]); !strings.ContainsRune(args[0], filepath.Separator) {
+		// the tools of the version win over the ones on PATH
+		if p, err2 := exec.LookPath(candidate); err2 == nil {
+			path, err = p, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G204
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"GOROOT="+goroot,
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+	)
+	tracer.Event("exec", "version", version, "path", path)
+	g.touchVersion(version)
+	return cmd, nil
+}
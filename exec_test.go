@@ -0,0 +1,45 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestExecCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake go is a shell script")
+	}
+	home := t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	for _, v := range []string{"1.20.3", "1.21.1", "1.21.4"} {
+		bin := filepath.Join(home, "versions", v, "go", "bin")
+		if err := os.MkdirAll(bin, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(bin, "go"), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// selectors pick among the installed versions, not the published ones
+	for selector, want := range map[string]string{"1.21.x": "1.21.4", "latest": "1.21.4", "1.20.3": "1.20.3", "~1.21.0": "1.21.4"} {
+		cmd, err := g.ExecCommand(selector, []string{"go", "version"})
+		if err != nil {
+			t.Errorf("%s: %v", selector, err)
+			continue
+		}
+		if goroot := filepath.Join(home, "versions", want, "go"); cmd.Path != filepath.Join(goroot, "bin", "go") {
+			t.Errorf("%s: runs %s, want %s", selector, cmd.Path, want)
+		}
+	}
+	if _, err := g.ExecCommand("1.19.2", []string{"go", "version"}); err == nil || err.Error() != T("exec.not_installed", "1.19.2") {
+		t.Errorf("missing version: %v", err)
+	}
+}
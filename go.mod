@@ -3,8 +3,8 @@ module govm
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.0.0
 	github.com/Masterminds/semver v1.5.0
-	github.com/g-lib/homedir v0.0.0-20201223145809-7fea0a36db32
 	github.com/k0kubun/go-ansi v0.0.0-20180517002512-3bf9e2903213
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db
 	github.com/schollz/progressbar/v3 v3.9.0
@@ -5,24 +5,23 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"runtime"
-	"sort"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/Masterminds/semver"
 	"github.com/c4milo/unpackit"
 )
 
 const (
 	goVMDir             string = ".govm"
 	defaultRegistryPath string = "https://golang.org/dl/"
-	goVMDownloadUrl     string = "https://github.com/TaceyWong/govm/releases/latest/download/"
-	goVMTagsApi         string = "https://raw.githubusercontent.com/TaceyWong/govm/go-tags/tags.json"
+	goVMReleasesUrl     string = "https://github.com/TaceyWong/govm/releases/"
+	goVMReleasesApi     string = "https://api.github.com/repos/TaceyWong/govm/releases"
+	defaultTagsApi      string = "https://raw.githubusercontent.com/TaceyWong/govm/go-tags/tags.json"
 )
 
 // Command ...
@@ -33,500 +32,748 @@ type Command interface {
 	Uninstall(version string)
 	Install(version string)
 	Use(version string)
-	Upgrade()
+	Upgrade(currentVersion string, opts UpgradeOptions) error
 	Helper
 }
 
 // GoVM struct
 type GoVM struct {
-	homeDir       string
+	layout        Layout
 	installDir    string
 	versionsDir   string
 	currentDir    string
 	currentBinDir string
 	currentGoDir  string
 	downloadsDir  string
+	binDir        string
+	logsDir       string
+	cacheDir      string
+	registry      string
+	updateChannel string
+	updateCheck   bool
+	config        Config
+	// systemVersionsDir is the shared store, system makes it the target of installs
+	systemVersionsDir string
+	system            bool
 	Command
 }
 
+// Options override the defaults NewGoVm derives from the environment.
+type Options struct {
+	// Home is the govm installation directory, see ResolveLayout for the default.
+	Home string
+	// Registry is the base URL Go archives are downloaded from.
+	Registry string
+	// System installs into and uninstalls from the system store.
+	System bool
+}
+
+// EnvInfo describes the directories and settings a GoVM works with
+type EnvInfo struct {
+	Home        string `json:"home"`
+	VersionsDir string `json:"versions_dir"`
+	CurrentDir  string `json:"current_dir"`
+	LogsDir     string `json:"logs_dir"`
+	Registry    string `json:"registry"`
+	Config      string `json:"config"`
+	SystemStore string `json:"system_store"`
+	BinDir      string `json:"bin_dir"`
+	CacheDir    string `json:"cache_dir"`
+	Arch        string `json:"arch"`
+	Current     string `json:"current"`
+}
+
+// Stability of a Go version
+const (
+	StabilityStable     = "stable"
+	StabilityPrerelease = "prerelease"
+)
+
+// InstalledVersion is a version found in the versions directory
+type InstalledVersion struct {
+	Version     string    `json:"version"`
+	Stability   string    `json:"stability"`
+	Path        string    `json:"path"`
+	Store       string    `json:"store"`
+	SizeBytes   int64     `json:"size_bytes,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+	// LastUsed is when use or exec last ran the version, nil if never
+	LastUsed *time.Time `json:"last_used,omitempty"`
+	Source   string     `json:"source,omitempty"`
+	Mirror   string     `json:"mirror,omitempty"`
+	SHA256   string     `json:"sha256,omitempty"`
+	Import   string     `json:"import,omitempty"`
+	Aliases  []string   `json:"aliases,omitempty"`
+	// PinnedBy lists the files of the projects in projects.dirs pinning the version
+	PinnedBy []string `json:"pinned_by,omitempty"`
+	Active   bool     `json:"active"`
+	// EOL and Vulns are what audit finds with its cached data, see Audit
+	EOL   bool     `json:"eol"`
+	Vulns []string `json:"vulns,omitempty"`
+}
+
+// VersionGroup holds the remote versions of one minor version, e.g. 1.17
+type VersionGroup struct {
+	Minor    string          `json:"minor"`
+	Versions []RemoteVersion `json:"versions"`
+}
+
+// RemoteVersion is a version published upstream
+type RemoteVersion struct {
+	Version   string `json:"version"`
+	Stability string `json:"stability"`
+	Installed bool   `json:"installed"`
+}
+
 // Helper ...
 type Helper interface {
 	getArch() string
 	existsVersion(version string) bool
 	cleanVersionDir(version string)
-	mkdirs(version string)
+	mkdirs()
 	getVersionDir(version string) string
-	downloadAndExtract(version string)
+	downloadAndExtract(version, dstDir, cachedSHA256 string) error
 	changeSymblinkGoBin(version string)
 	changeSymblinkGo(version string)
-	getLatestVersion() string
+	latestRelease(channel string) (string, error)
 	getGithubTags(repo string) (result []string)
 }
 
 var gvm GoVM
 var githubTags map[string][]string
 
-// NewVM instance
+// NewGoVm instance using the default locations
 func NewGoVm() GoVM {
-	gvm.homeDir = os.Getenv("HOME")
-	gvm.installDir = filepath.Join(gvm.homeDir, goVMDir)
-	gvm.versionsDir = filepath.Join(gvm.installDir, "versions")
-	gvm.currentDir = filepath.Join(gvm.installDir, "current")
-	gvm.currentBinDir = filepath.Join(gvm.installDir, "current", "bin")
-	gvm.currentGoDir = filepath.Join(gvm.installDir, "current", "go")
-	gvm.downloadsDir = filepath.Join(gvm.installDir, "downloads")
-
-	return gvm
-}
-
-func (g *GoVM) getArch() string {
-	return runtime.GOOS + "-" + runtime.GOARCH
+	g, err := NewGoVmWithOptions(Options{})
+	if err != nil {
+		ErrorT("cli.error", err)
+		os.Exit(1)
+	}
+	return g
 }
 
-// ListVersions that are installed by dir ls
-// highlight the version that is currently symbolic linked
-func (g *GoVM) ListVersions() error {
-	entries, err := os.ReadDir(g.versionsDir)
-	CheckError(err, "[Error]: List versions failed")
-	files := make([]fs.FileInfo, 0, len(entries))
-	for _, entry := range entries {
-		info, err := entry.Info()
-		CheckError(err, "[Error]: List versions failed")
-		files = append(files, info)
+// NewGoVmWithOptions instance, falling back to the defaults for empty options.
+// It fails when no home directory can be found, an invalid config is replaced
+// by the defaults as LoadConfig reports it to the caller.
+func NewGoVmWithOptions(opts Options) (GoVM, error) {
+	layout, err := ResolveLayout(opts.Home)
+	if err != nil {
+		return gvm, err
 	}
+	gvm.setLayout(layout)
 
-	cv := g.CurrentVersion()
-
-	versionsSemantic := make([]*semver.Version, 0)
-
-	for _, f := range files {
-		if v, err := semver.NewVersion(f.Name()); err == nil {
-			versionsSemantic = append(versionsSemantic, v)
-		}
+	cfg, err := LoadConfig(layout)
+	if err != nil {
+		cfg = DefaultConfig()
+	}
+	gvm.config = cfg
+	if cfg.UI.Locale != "" {
+		SetLocale(cfg.UI.Locale)
 	}
+	httpClient = newHTTPClient(cfg.Network.Proxy)
 
-	// sort semantic versions
-	sort.Sort(semver.Collection(versionsSemantic))
+	gvm.registry = cfg.Mirror.Registry
+	if opts.Registry != "" {
+		gvm.registry = opts.Registry
+	}
+	if !strings.HasSuffix(gvm.registry, "/") {
+		gvm.registry += "/"
+	}
 
-	for _, versionSemantic := range versionsSemantic {
-		version := versionSemantic.String()
-		// 1.8.0 -> 1.8
-		reMajorVersion, _ := regexp.Compile("[0-9]+.[0-9]+.0")
-		if reMajorVersion.MatchString(version) {
-			version = strings.Split(version, ".")[0] + "." + strings.Split(version, ".")[1]
-		}
+	gvm.updateChannel = cfg.Update.Channel
+	gvm.updateCheck = cfg.Update.Check
 
-		if version == cv {
-			version = cv + "*"
-			Successln(version)
-		} else {
-			log.Println(version)
-		}
+	gvm.systemVersionsDir = filepath.Join(cfg.System.Store, "versions")
+	gvm.system = opts.System
+	if gvm.system {
+		gvm.versionsDir = gvm.systemVersionsDir
 	}
 
-	// print rc and beta versions in the end
-	for _, f := range files {
-		rcVersion := f.Name()
-		r, _ := regexp.Compile("beta.*|rc.*")
-		matches := r.FindAllString(rcVersion, -1)
-		if len(matches) == 1 {
-			if rcVersion == cv {
-				rcVersion = cv + "*"
-				Successln(rcVersion)
-			} else {
-				log.Println(rcVersion)
-			}
-		}
-	}
+	return gvm, nil
+}
 
-	if cv != "" {
-		log.Println()
-		log.Printf("current: %s", cv)
+// Env reports the directories and settings in use
+func (g *GoVM) Env() EnvInfo {
+	return EnvInfo{
+		Home:        g.installDir,
+		VersionsDir: g.versionsDir,
+		CurrentDir:  g.currentDir,
+		LogsDir:     g.logsDir,
+		Registry:    g.registry,
+		Config:      g.config.Path(),
+		SystemStore: g.config.System.Store,
+		BinDir:      g.binDir,
+		CacheDir:    g.cacheDir,
+		Arch:        g.getArch(),
+		Current:     g.CurrentVersion(),
 	}
-	return nil
 }
 
-// ListRemoteVersions that are installed by dir ls
-func (g *GoVM) ListRemoteVersions(print bool) map[string][]string {
-	log.Println("[Info]: Fetching remote versions")
-	tags := g.getGithubTags("golang/go")
+// Config in use, loaded from config.toml and the environment
+func (g *GoVM) Config() Config {
+	return g.config
+}
 
-	var versions []string
-	for _, tag := range tags {
-		versions = append(versions, strings.ReplaceAll(tag, "go", ""))
-	}
+func (g *GoVM) getArch() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
 
-	return g.getGroupedVersion(versions, print)
+// ListVersions that are installed by dir ls
+// highlight the version that is currently symbolic linked
+func (g *GoVM) ListVersions() error {
+	versions, err := g.InstalledVersions(false)
+	if err != nil {
+		return err
+	}
+	PrintInstalledVersions(reporter.Out, versions)
+	return nil
 }
 
-func (g *GoVM) getGroupedVersion(versions []string, print bool) map[string][]string {
-	groupedVersions := make(map[string][]string)
-	for _, version := range versions {
-		parts := strings.Split(version, ".")
-		if len(parts) > 1 {
-			majorVersion := fmt.Sprintf("%s.%s", parts[0], parts[1])
-			r, _ := regexp.Compile("beta.*|rc.*")
-			matches := r.FindAllString(majorVersion, -1)
-			if len(matches) == 1 {
-				majorVersion = strings.Split(version, matches[0])[0]
+// InstalledVersions reads the versions directories of every store in Go
+// release order, see Version.Compare.
+// A version installed in both stores is listed twice, the user store first.
+// detailed also collects the size on disk, which walks every file of each version,
+// and the projects pinning each version
+func (g *GoVM) InstalledVersions(detailed bool) ([]InstalledVersion, error) {
+	stores := make(map[string][]string)
+	var names []string
+	for _, s := range g.stores() {
+		entries, err := os.ReadDir(s.dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", T("list.failed"), err)
+		}
+		for _, entry := range entries {
+			// hidden directories are staged installs and repairs
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
 			}
-			groupedVersions[majorVersion] = append(groupedVersions[majorVersion], version)
+			if _, ok := stores[entry.Name()]; !ok {
+				names = append(names, entry.Name())
+			}
+			stores[entry.Name()] = append(stores[entry.Name()], s.name)
 		}
 	}
 
-	// groupedVersionKeys := []string{"1", "1.1", "1.2", ..., "1.17"}
-	groupedVersionKeys := make([]string, 0, len(groupedVersions))
-	for groupedVersionKey := range groupedVersions {
-		groupedVersionKeys = append(groupedVersionKeys, groupedVersionKey)
+	currentDir := g.currentVersionDir()
+	used := g.lastUsed()
+	aliases := g.aliasesByVersion()
+	versions := make([]InstalledVersion, 0, len(names))
+	for _, name := range sortVersionNames(names) {
+		for _, store := range stores[name] {
+			v := g.installedVersion(name, store, currentDir)
+			if t, ok := used[name]; ok {
+				v.LastUsed = &t
+			}
+			v.Aliases = aliases[name]
+			versions = append(versions, v)
+		}
 	}
-
-	versionsSemantic := make([]*semver.Version, 0)
-	for _, r := range groupedVersionKeys {
-		if v, err := semver.NewVersion(r); err == nil {
-			versionsSemantic = append(versionsSemantic, v)
+	g.auditInstalled(versions)
+	if detailed {
+		g.forEachParallel(len(versions), func(i int) {
+			versions[i].SizeBytes = dirSize(versions[i].Path)
+		})
+		pins, err := findPins(g.config.Projects.Dirs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", T("list.failed"), err)
+		}
+		pinned := pinnedVersions(versions, pins)
+		for i := range versions {
+			versions[i].PinnedBy = pinned[versions[i].Version]
 		}
 	}
+	return versions, nil
+}
 
-	// sort semantic versions
-	sort.Sort(semver.Collection(versionsSemantic))
-
-	// match 1.0.0 or 2.0.0
-	reTopVersion, _ := regexp.Compile("[0-9]+.0.0")
-
-	for _, versionSemantic := range versionsSemantic {
-		maxPerLine := 0
-		strKey := versionSemantic.String()
-		lookupKey := ""
-		versionParts := strings.Split(strKey, ".")
+func (g *GoVM) installedVersion(name, store, currentDir string) InstalledVersion {
+	dir := filepath.Join(g.versionsDir, name)
+	if store == StoreSystem {
+		dir = filepath.Join(g.systemVersionsDir, name)
+	}
+	iv := InstalledVersion{
+		Version:   name,
+		Stability: versionStability(name),
+		Path:      dir,
+		Store:     store,
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil && resolved == currentDir {
+		iv.Active = true
+	}
+	if r, err := readReceiptFile(filepath.Join(dir, receiptFile)); err == nil {
+		iv.InstalledAt = r.InstalledAt
+		iv.Source = r.Source
+		iv.Mirror = r.Mirror
+		iv.SHA256 = r.SHA256
+		iv.Import = r.Import
+	} else if info, err := os.Stat(iv.Path); err == nil {
+		iv.InstalledAt = info.ModTime()
+	}
+	return iv
+}
 
-		// prepare lookup key for the grouped version map.
-		// 1.0.0 -> 1.0, 1.1.1 -> 1.1
-		lookupKey = versionParts[0] + "." + versionParts[1]
-		// On match 1.0.0, print 1. On match 2.0.0 print 2
-		if reTopVersion.MatchString(strKey) {
-			if print {
-				Major(versionParts[0])
-			}
-			g.print("\t", print)
-		} else {
-			if print {
-				Major(lookupKey)
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
 			}
-			g.print("\t", print)
 		}
+		return nil
+	})
+	return size
+}
 
-		groupedVersionsSemantic := make([]*semver.Version, 0)
-		for _, r := range groupedVersions[lookupKey] {
-			if v, err := semver.NewVersion(r); err == nil {
-				groupedVersionsSemantic = append(groupedVersionsSemantic, v)
+// forEachParallel calls fn for 0 <= i < n on at most Parallelism goroutines
+func (g *GoVM) forEachParallel(n int, fn func(i int)) {
+	workers := g.config.Parallelism
+	if workers < 1 {
+		workers = 1
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
 			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
 
-		}
-		// sort semantic versions
-		sort.Sort(semver.Collection(groupedVersionsSemantic))
-
-		for _, gvSemantic := range groupedVersionsSemantic {
-			maxPerLine++
-			if maxPerLine == 6 {
-				maxPerLine = 0
-				g.print("\n\t", print)
-			}
-			g.print(gvSemantic.String()+"  ", print)
-		}
+// ListRemoteVersions fetches the available versions grouped by minor version
+func (g *GoVM) ListRemoteVersions(print bool) map[string][]string {
+	groups := g.RemoteVersions()
+	if print {
+		PrintVersionGroups(reporter.Out, groups, false)
+	}
 
-		// print rc and beta versions in the end
-		for _, rcVersion := range groupedVersions[lookupKey] {
-			r, _ := regexp.Compile("beta.*|rc.*")
-			matches := r.FindAllString(rcVersion, -1)
-			if len(matches) == 1 {
-				g.print(rcVersion+"  ", print)
-				maxPerLine++
-				if maxPerLine == 6 {
-					maxPerLine = 0
-					g.print("\n\t", print)
-				}
-			}
+	groupedVersions := make(map[string][]string, len(groups))
+	for _, group := range groups {
+		for _, v := range group.Versions {
+			groupedVersions[group.Minor] = append(groupedVersions[group.Minor], v.Version)
 		}
-		g.print("\n", print)
-		g.print("\n", print)
 	}
 	return groupedVersions
 }
 
-func (g *GoVM) print(message string, shouldPrint bool) {
-	if shouldPrint {
-		fmt.Print(message)
+// RemoteVersions fetches the available versions grouped by minor version,
+// groups and the versions within them in Go release order
+func (g *GoVM) RemoteVersions() []VersionGroup {
+	// the zero filter fetches nothing but the versions, it never fails
+	groups, _ := g.FilterRemoteVersions(RemoteFilter{})
+	return groups
+}
+
+// remoteVersionNames are the go tags without the go prefix, e.g. 1.17.6
+func (g *GoVM) remoteVersionNames() []string {
+	var versions []string
+	for _, tag := range g.getGithubTags("golang/go") {
+		versions = append(versions, strings.ReplaceAll(tag, "go", ""))
 	}
+	return versions
 }
 
-func (g *GoVM) existsVersion(version string) bool {
-	path := filepath.Join(g.versionsDir, version, "go")
-	_, err := os.Stat(path)
-	if err == nil {
-		return true
+// groupVersions groups versions by minor version, the groups and the
+// versions in them oldest first
+func groupVersions(versions []string) []VersionGroup {
+	groupedVersions := make(map[string][]string)
+	var minors []string
+	for _, version := range sortVersionNames(versions) {
+		v, _ := ParseVersion(version)
+		minor := v.MinorVersion()
+		if _, ok := groupedVersions[minor]; !ok {
+			minors = append(minors, minor)
+		}
+		groupedVersions[minor] = append(groupedVersions[minor], version)
 	}
-	if os.IsNotExist(err) {
-		return false
+
+	groups := make([]VersionGroup, 0, len(minors))
+	for _, minor := range minors {
+		group := VersionGroup{Minor: minor}
+		for _, version := range groupedVersions[minor] {
+			group.Versions = append(group.Versions, RemoteVersion{
+				Version:   version,
+				Stability: versionStability(version),
+			})
+		}
+		groups = append(groups, group)
 	}
-	return false
+	return groups
+}
+
+func (g *GoVM) existsVersion(version string) bool {
+	_, _, ok := g.findVersion(version)
+	return ok
 }
 
 // CurrentVersion get current version from symb link
 func (g *GoVM) CurrentVersion() string {
+	dir := g.currentVersionDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Base(dir)
+}
 
-	fp, err := filepath.EvalSymlinks(g.currentBinDir)
+// currentVersionDir is the resolved directory of the active version in any store,
+// current/bin links to <store>/versions/<version>/go/bin. Only the link itself
+// is followed, the go tree of a linked import lives elsewhere.
+func (g *GoVM) currentVersionDir() string {
+	target, err := os.Readlink(g.currentBinDir)
 	if err != nil {
 		return ""
 	}
-
-	version := strings.ReplaceAll(fp, "/go/bin", "")
-	version = strings.ReplaceAll(version, g.versionsDir, "")
-	version = strings.ReplaceAll(version, "/", "")
-	return version
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(g.currentBinDir), target)
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(filepath.Dir(target)))
+	if err != nil {
+		return ""
+	}
+	return dir
 }
 
 // Uninstall the given version of go
 func (g *GoVM) Uninstall(version string) {
 	if version == "" {
-		log.Fatal("[Error] No version provided")
+		ErrorT("version.missing")
+		os.Exit(1)
 	}
+	version = normalizeVersion(version)
 	if g.CurrentVersion() == version {
-		Errorf("[Error] Version: %s you are trying to remove is your current version. Please use a different version first before uninstalling the current version\n", version)
+		ErrorT("uninstall.current", version)
+		os.Exit(1)
+	}
+	_, store, ok := g.findVersion(version)
+	if !ok {
+		ErrorT("uninstall.not_installed", version)
 		os.Exit(1)
 	}
-	if !g.existsVersion(version) {
-		Errorf("[Error] Version: %s you are trying to remove is not installed\n", version)
+	if store == StoreSystem && !g.system {
+		ErrorT("uninstall.system_version", version)
 		os.Exit(1)
 	}
+	if g.system {
+		if err := g.checkSystemStore(); err != nil {
+			ErrorT("cli.error", err)
+			os.Exit(1)
+		}
+	}
+	dir, _, _ := g.findVersion(version)
+	linked := linkedTree(dir)
+	target, _ := os.Readlink(filepath.Join(dir, "go"))
 	g.cleanVersionDir(version)
-	Successf("[Success] Version: %s uninstalled\n", version)
+	g.forgetRemovedVersion(version)
+	SuccessT("uninstall.done", version)
+	if linked {
+		InfoT("uninstall.linked", target)
+	}
+	removed, freed, err := g.CollectGarbage()
+	if err != nil {
+		ErrorT("store.gc_failed", err)
+	} else if removed > 0 {
+		InfoT("store.gc", removed, FormatBytes(freed))
+	}
 }
 
+// cleanVersionDir removes a version, RemoveAll removes the link of a
+// linked import and not the tree it links to
 func (g *GoVM) cleanVersionDir(version string) {
-	_ = os.RemoveAll(g.getVersionDir(version))
+	err := os.RemoveAll(g.getVersionDir(version))
+	traceFS("remove", g.getVersionDir(version), err)
 }
 
 func (g *GoVM) cleanDownloadsDir() {
-	_ = os.RemoveAll(g.downloadsDir)
+	err := os.RemoveAll(g.downloadsDir)
+	traceFS("remove", g.downloadsDir, err)
 }
 
-// Install the given version of go
+// Install the given version of go, exiting when it fails
 func (g *GoVM) Install(version string) {
 	if version == "" {
-		log.Fatal("[Error] No version provided")
+		ErrorT("version.missing")
+		os.Exit(1)
 	}
-	version = g.judgeVersion(version)
-	g.mkdirs(version)
-	if g.existsVersion(version) {
-		Infof("[Info] Version: %s exists \n", version)
-		return
+	if err := g.install(g.judgeVersion(version)); err != nil {
+		os.Exit(1)
 	}
-
-	Infof("[Info] Downloading version: %s \n", version)
-	g.downloadAndExtract(version)
-	g.cleanDownloadsDir()
-	Successf("[Success] Downloaded version: %s\n", version)
 }
 
-func (g *GoVM) judgeVersion(version string) string {
-	judgedVersion := ""
-	rcBetaOk := false
-	reRcOrBeta, _ := regexp.Compile("beta.*|rc.*")
-	// check if version string ends with x
-
-	if strings.HasSuffix(version, "x") {
-		judgedVersion = version[:len(version)-1]
+// install installs the resolved version unless it is installed already,
+// errors are reported before they are returned
+func (g *GoVM) install(version string) error {
+	if _, store, ok := g.findVersion(version); ok {
+		if store == StoreSystem && !g.system {
+			InfoT("install.exists_system", version, g.systemVersionsDir)
+		} else {
+			InfoT("install.exists", version)
+		}
+		return nil
 	}
-
-	if strings.HasSuffix(version, ".x") {
-		judgedVersion = version[:len(version)-2]
+	if g.system {
+		if err := g.checkSystemStore(); err != nil {
+			ErrorT("cli.error", err)
+			return err
+		}
 	}
-	if strings.HasSuffix(version, "@latest") {
-		judgedVersion = version[:len(version)-7]
+	g.mkdirs()
+	g.cleanStaleStaging()
+	unlock, err := g.lockVersion(version)
+	if err != nil {
+		ErrorT("cli.error", err)
+		return err
 	}
-	if strings.HasSuffix(version, "@dev-latest") {
-		judgedVersion = version[:len(version)-11]
-		rcBetaOk = true
+	defer unlock()
+	// another govm may have installed it while this one waited for the lock
+	if _, _, ok := g.findVersion(version); ok {
+		InfoT("install.exists", version)
+		return nil
 	}
 
-	if version == "latest" || version == "dev-latest" {
-		groupedVersions := g.ListRemoteVersions(false) // donot print
-		groupedVersionKeys := make([]string, 0, len(groupedVersions))
-		for groupedVersionKey := range groupedVersions {
-			groupedVersionKeys = append(groupedVersionKeys, groupedVersionKey)
-		}
-		versionsSemantic := make([]*semver.Version, 0)
-		for _, r := range groupedVersionKeys {
-			if v, err := semver.NewVersion(r); err == nil {
-				versionsSemantic = append(versionsSemantic, v)
-			}
-		}
-
-		// sort semantic versions
-		sort.Sort(semver.Collection(versionsSemantic))
-		// loop in reverse
-		for i := len(versionsSemantic) - 1; i >= 0; i-- {
-			judgedVersions := groupedVersions[versionsSemantic[i].Original()]
-			// get last element
-			if version == "dev-latest" {
-				return judgedVersions[len(judgedVersions)-1]
-			}
-
-			// loop in reverse
-			for j := len(judgedVersions) - 1; j >= 0; j-- {
-				matches := reRcOrBeta.FindAllString(judgedVersions[j], -1)
-				if len(matches) == 0 {
-					return judgedVersions[j]
-				}
-			}
-		}
-
-		latest := versionsSemantic[len(versionsSemantic)-1].String()
-		return g.judgeVersion(latest)
-	}
-
-	if judgedVersion != "" {
-		groupedVersions := g.ListRemoteVersions(false) // donot print
-		// check if judgedVersion is in the groupedVersions
-		if _, ok := groupedVersions[judgedVersion]; ok {
-			// get last item in the groupedVersions excluding rc and beta
-			// loop in reverse groupedVersions
-			for i := len(groupedVersions[judgedVersion]) - 1; i >= 0; i-- {
-				matches := reRcOrBeta.FindAllString(groupedVersions[judgedVersion][i], -1)
-				if len(matches) == 0 {
-					return groupedVersions[judgedVersion][i]
-				}
-			}
-			if rcBetaOk {
-				// return last element including beta and rc if present
-				return groupedVersions[judgedVersion][len(groupedVersions[judgedVersion])-1]
-			}
-		}
+	if err := g.runHook("pre_install", g.config.Hooks.PreInstall, version); err != nil {
+		ErrorT("hook.failed", "pre_install", err)
+		return err
 	}
-
-	return version
+	InfoT("install.downloading", version)
+	staging, err := g.stageVersion(version, "", nil)
+	if err != nil {
+		return err
+	}
+	if err := g.commitStaging(staging, version); err != nil {
+		ErrorT("install.commit_failed", version, err)
+		return err
+	}
+	SuccessT("install.done", version)
+	if err := g.runHook("post_install", g.config.Hooks.PostInstall, version); err != nil {
+		ErrorT("hook.failed", "post_install", err)
+	}
+	return nil
 }
 
-// Use a version
-func (g *GoVM) Use(version string) {
-	version = g.judgeVersion(version)
-	if g.CurrentVersion() == version {
-		Infof("[Info] Version: %s is already your current version \n", version)
+// finishInstall prepares the version extracted into dir: readable by every
+// user in the system store, linked to the store with store.dedupe except for
+// the files in skip, and its manifest recorded for verify. Failures are
+// reported, the version stays usable.
+func (g *GoVM) finishInstall(version, dir string, skip map[string]bool) {
+	// a linked import stays as the user has it
+	linked := linkedTree(dir)
+	if g.system && !linked {
+		// the system store is shared, every user must be able to run it
+		if err := makeReadable(dir); err != nil {
+			ErrorT("install.chmod_failed", err)
+		}
+	}
+	m, err := g.buildManifest(version, dir)
+	if err != nil {
+		ErrorT("install.manifest_failed", err)
 		return
 	}
-	Infof("[Info] Changing go version to: %s \n", version)
-	g.changeSymblinkGoBin(version)
-	g.changeSymblinkGo(version)
-	Successf("[Success] Changed go version to: %s\n", version)
+	if g.config.Store.Dedupe && !linked {
+		// the copied tree is complete, deduplication only saves space
+		if err := g.dedupe(dir, &m, skip); err != nil {
+			ErrorT("store.dedupe_failed", version, err)
+		}
+	}
+	// written last, the linked files have the read-only mode of their blob
+	if err := writeManifest(dir, m); err != nil {
+		ErrorT("install.manifest_failed", err)
+	}
 }
 
-// Upgrade of GoBrew
-func (g *GoVM) Upgrade(currentVersion string) {
-	if "v"+currentVersion == g.getLatestVersion() {
-		Infoln("[INFO] your version is already newest")
-		return
+// judgeVersion resolves the selector version, see ParseSelector, and exits
+// when it is invalid or matches no published version
+func (g *GoVM) judgeVersion(version string) string {
+	resolved, err := g.resolveVersion(version)
+	if err != nil {
+		ErrorT("cli.error", err)
+		os.Exit(1)
 	}
+	return resolved
+}
 
-	mkdirTemp, _ := os.MkdirTemp("", "govm")
-	tmpFile := filepath.Join(mkdirTemp, "gobrew")
-	url := goVMDownloadUrl + "govm-" + g.getArch()
-	if err := DownloadWithProgress(url, "gobrew", mkdirTemp); err != nil {
-		Errorln("[Error] Download GoBrew failed:", err)
-		return
+// resolveVersion resolves a selector or an alias, only the selectors other
+// than exact versions fetch the published versions
+func (g *GoVM) resolveVersion(selector string) (string, error) {
+	if v, ok := g.aliasVersion(selector); ok {
+		return v, nil
 	}
-
-	source, err := os.Open(tmpFile)
+	sel, err := ParseSelector(selector)
 	if err != nil {
-		Errorln("[Error] Cannot open file", err)
-		return
+		return "", err
 	}
-	defer func(source *os.File) {
-		_ = source.Close()
-	}(source)
+	if v, ok := sel.Exact(); ok {
+		return v, nil
+	}
+	InfoT("remote.fetching")
+	return sel.Resolve(g.remoteVersionNames())
+}
 
-	goBrewFile := filepath.Join(g.installDir, "/bin/gobrew")
-	destination, err := os.Create(goBrewFile)
+// resolveInstalled resolves selector against the installed versions, a
+// selector none of them matches is resolved against the remote ones to name
+// the version that is missing
+func (g *GoVM) resolveInstalled(selector string) (string, error) {
+	if v, ok := g.aliasVersion(selector); ok {
+		return v, nil
+	}
+	sel, err := ParseSelector(selector)
 	if err != nil {
-		Errorf("[Error] Cannot open file: %s", err)
-		return
+		return "", err
 	}
-	defer func(destination *os.File) {
-		_ = destination.Close()
-	}(destination)
-
-	if _, err = io.Copy(destination, source); err != nil {
-		Errorf("[Error] Cannot copy file: %s", err)
-		return
+	var names []string
+	if versions, err := g.InstalledVersions(false); err == nil {
+		for _, v := range versions {
+			names = append(names, v.Version)
+		}
 	}
-
-	if err = os.Chmod(goBrewFile, 0755); err != nil {
-		Errorf("[Error] Cannot set file as executable: %s", err)
-		return
+	if v, err := sel.Resolve(names); err == nil {
+		return v, nil
 	}
+	return g.resolveVersion(selector)
+}
 
-	if err = os.Remove(tmpFile); err != nil {
-		Errorf("[Error] Cannot remove tmp file: %s", err)
+// Use a version
+func (g *GoVM) Use(version string) {
+	version = g.judgeVersion(version)
+	if g.CurrentVersion() == version {
+		g.touchVersion(version)
+		InfoT("use.already", version)
 		return
 	}
-
-	Infoln("Upgrade successful")
+	InfoT("use.changing", version)
+	// the version may come from the system store, which leaves the home untouched
+	err := os.MkdirAll(g.currentDir, os.ModePerm)
+	traceFS("mkdir", g.currentDir, err)
+	g.changeSymblinkGoBin(version)
+	g.changeSymblinkGo(version)
+	g.touchVersion(version)
+	SuccessT("use.done", version)
+	if err := g.runHook("post_use", g.config.Hooks.PostUse, version); err != nil {
+		ErrorT("hook.failed", "post_use", err)
+	}
 }
 
-func (g *GoVM) mkdirs(version string) {
-	_ = os.MkdirAll(g.installDir, os.ModePerm)
-	_ = os.MkdirAll(g.currentDir, os.ModePerm)
-	_ = os.MkdirAll(g.versionsDir, os.ModePerm)
-	_ = os.MkdirAll(g.getVersionDir(version), os.ModePerm)
-	_ = os.MkdirAll(g.downloadsDir, os.ModePerm)
+func (g *GoVM) mkdirs() {
+	dirs := []string{g.installDir, g.currentDir, g.versionsDir, g.downloadsDir}
+	if g.system {
+		dirs = []string{g.versionsDir, g.downloadsDir}
+	}
+	for _, dir := range dirs {
+		err := os.MkdirAll(dir, os.ModePerm)
+		traceFS("mkdir", dir, err)
+	}
 }
 
 func (g *GoVM) getVersionDir(version string) string {
 	return filepath.Join(g.versionsDir, version)
 }
-func (g *GoVM) downloadAndExtract(version string) {
+
+// downloadAndExtract extracts the archive of version into dstDir and writes
+// the receipt. A kept archive is reused with keep_downloads or when it
+// matches cachedSHA256. Errors are reported before they are returned.
+func (g *GoVM) downloadAndExtract(version, dstDir, cachedSHA256 string) error {
 	tarName := "go" + version + "." + g.getArch() + ".tar.gz"
 
-	registryPath := defaultRegistryPath
-	if p := os.Getenv("GOBREW_REGISTRY"); p != "" {
-		registryPath = p
+	srcTar := filepath.Join(g.downloadsDir, tarName)
+	mirror := g.registry
+	downloadURL := mirror + tarName
+	if g.reusableArchive(srcTar, cachedSHA256) {
+		DebugT("install.cached_download", srcTar)
+	} else {
+		dstDownloadDir := filepath.Join(g.downloadsDir)
+		DebugT("install.download_to", dstDownloadDir)
+
+		// the registry first, then the fallback mirrors in order
+		var err error
+		for i, m := range append([]string{g.registry}, g.config.Mirror.Fallbacks...) {
+			if !strings.HasSuffix(m, "/") {
+				m += "/"
+			}
+			mirror, downloadURL = m, m+tarName
+			if i > 0 {
+				InfoT("install.trying_mirror", mirror)
+			}
+			tracer.Event("download", "version", version, "url", downloadURL, "mirror", mirror)
+			DebugT("install.download_from", downloadURL)
+			if err = DownloadWithProgress(downloadURL, tarName, dstDownloadDir); err == nil {
+				break
+			}
+			ErrorT("install.download_failed", err)
+		}
+
+		if err != nil {
+			ErrorT("install.check_connectivity", downloadURL)
+			return err
+		}
 	}
-	downloadURL := registryPath + tarName
-	Infof("[Info] Downloading from: %s \n", downloadURL)
 
-	dstDownloadDir := filepath.Join(g.downloadsDir)
-	Infof("[Info] Downloading to: %s \n", dstDownloadDir)
-	err := DownloadWithProgress(downloadURL, tarName, dstDownloadDir)
+	DebugT("install.extract_from", srcTar)
+	DebugT("install.extract_to", dstDir)
 
+	err := g.ExtractTarGz(srcTar, dstDir)
 	if err != nil {
-		g.cleanVersionDir(version)
-		Infof("[Info]: Downloading version failed: %s \n", err)
-		Errorf("[Error]: Please check connectivity to url: %s\n", downloadURL)
-		os.Exit(1)
+		// a broken archive must not be reused
+		_ = os.Remove(srcTar)
+		ErrorT("install.extract_failed", err)
+		ErrorT("install.check_version", downloadURL)
+		return err
 	}
+	InfoT("install.extracted", dstDir)
 
-	srcTar := filepath.Join(g.downloadsDir, tarName)
-	dstDir := g.getVersionDir(version)
-
-	Infof("[Info] Extracting from: %s \n", srcTar)
-	Infof("[Info] Extracting to: %s \n", dstDir)
+	receipt := Receipt{
+		Version:     version,
+		Source:      downloadURL,
+		Mirror:      mirror,
+		InstalledAt: time.Now(),
+	}
+	if sum, err := fileSHA256(srcTar); err == nil {
+		receipt.SHA256 = sum
+	}
+	if err := writeReceiptFile(dstDir, receipt); err != nil {
+		ErrorT("install.receipt_failed", err)
+	}
+	return nil
+}
 
-	err = g.ExtractTarGz(srcTar, dstDir)
-	if err != nil {
-		// clean up dir
-		g.cleanVersionDir(version)
-		Infof("[Info]: Untar failed: %s \n", err)
-		Errorf("[Error]: Please check if version exists from url: %s\n", downloadURL)
-		os.Exit(1)
+// reusableArchive tells whether the archive at path can be extracted again
+func (g *GoVM) reusableArchive(path, sha256 string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	if g.config.Cache.KeepDownloads {
+		return true
 	}
-	Infof("[Success] Untar to %s\n", g.getVersionDir(version))
+	sum, err := fileSHA256(path)
+	return err == nil && sha256 != "" && sum == sha256
 }
 
-func (g *GoVM) ExtractTarGz(srcTar string, dstDir string) error {
+func (g *GoVM) ExtractTarGz(srcTar string, dstDir string) (err error) {
+	start := time.Now()
+	defer func() {
+		traceFS("extract", dstDir, err, "archive", srcTar, "duration", time.Since(start))
+	}()
+
 	//#nosec G304
 	file, err := os.Open(srcTar)
 	if err != nil {
 		return err
 	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
 	_, err = unpackit.Unpack(file, dstDir)
 	if err != nil {
 		return err
@@ -535,80 +782,89 @@ func (g *GoVM) ExtractTarGz(srcTar string, dstDir string) error {
 	return nil
 }
 
+// linkedVersionDir is where the current links of version point to, in any store
+func (g *GoVM) linkedVersionDir(version string) string {
+	if dir, _, ok := g.findVersion(version); ok {
+		return dir
+	}
+	return g.getVersionDir(version)
+}
+
 func (g *GoVM) changeSymblinkGoBin(version string) {
-	goBinDst := filepath.Join(g.versionsDir, version, "/go/bin")
+	goBinDst := filepath.Join(g.linkedVersionDir(version), "go", "bin")
 	_ = os.RemoveAll(g.currentBinDir)
 
-	if err := os.Symlink(goBinDst, g.currentBinDir); err != nil {
-		Errorf("[Error]: symbolic link failed: %s\n", err)
+	err := os.Symlink(goBinDst, g.currentBinDir)
+	traceFS("symlink", g.currentBinDir, err, "target", goBinDst)
+	if err != nil {
+		ErrorT("use.symlink_failed", err)
 		os.Exit(1)
 	}
 }
 
 func (g *GoVM) changeSymblinkGo(version string) {
 	_ = os.RemoveAll(g.currentGoDir)
-	versionGoDir := filepath.Join(g.versionsDir, version, "go")
+	versionGoDir := filepath.Join(g.linkedVersionDir(version), "go")
 
-	if err := os.Symlink(versionGoDir, g.currentGoDir); err != nil {
-		Errorf("[Error]: symbolic link failed: %s\n", err)
+	err := os.Symlink(versionGoDir, g.currentGoDir)
+	traceFS("symlink", g.currentGoDir, err, "target", versionGoDir)
+	if err != nil {
+		ErrorT("use.symlink_failed", err)
 		os.Exit(1)
 	}
 }
 
-func (g *GoVM) getLatestVersion() string {
-	tags := g.getGithubTags("kevincobain2000/gobrew")
-
-	if len(tags) == 0 {
-		return ""
-	}
-
-	return tags[len(tags)-1]
-}
-
 func (g *GoVM) getGithubTags(repo string) (result []string) {
 	if len(githubTags[repo]) > 0 {
 		return githubTags[repo]
 	}
 
 	githubTags = make(map[string][]string, 0)
-	client := &http.Client{}
 	url := "https://api.github.com/repos/TaceyWong/govm/git/refs/tags"
 	if repo == "golang/go" {
-		url = goVMTagsApi
+		url = g.config.Mirror.TagsAPI
 	}
-	request, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		Errorf("[Error] Cannot create request: %s", err)
-		return
+
+	type Tag struct {
+		Ref string
 	}
+	var tags []Tag
 
-	request.Header.Set("User-Agent", "govm")
+	cacheName := "tags-" + strings.ReplaceAll(repo, "/", "-") + ".json"
+	data, cached := g.readRemoteCache(cacheName)
+	if !cached {
+		request, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			ErrorT("tags.request_failed", err)
+			return
+		}
 
-	response, err := client.Do(request)
-	if err != nil {
-		Errorf("[Error] Cannot get response: %s", err)
-		return
-	}
+		request.Header.Set("User-Agent", "govm")
 
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(response.Body)
+		response, err := httpClient.Do(request)
+		if err != nil {
+			ErrorT("tags.response_failed", err)
+			return
+		}
 
-	data, err := io.ReadAll(response.Body)
-	if err != nil {
-		Errorf("[Error] Cannot read response: %s", err)
-		return
-	}
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close()
+		}(response.Body)
 
-	type Tag struct {
-		Ref string
+		data, err = io.ReadAll(response.Body)
+		if err != nil {
+			ErrorT("tags.read_failed", err)
+			return
+		}
 	}
-	var tags []Tag
 
 	if err := json.Unmarshal(data, &tags); err != nil {
-		Errorf("[Error] Rate limit exceed")
+		ErrorT("tags.rate_limit")
 		os.Exit(2)
 	}
+	if !cached {
+		g.writeRemoteCache(cacheName, data)
+	}
 
 	for _, tag := range tags {
 		t := strings.ReplaceAll(tag.Ref, "refs/tags/", "")
@@ -621,5 +877,32 @@ func (g *GoVM) getGithubTags(repo string) (result []string) {
 	return result
 }
 
+// readRemoteCache returns a response cached in the cache dir while it is
+// younger than cache.remote_ttl
+func (g *GoVM) readRemoteCache(name string) ([]byte, bool) {
+	ttl := g.config.RemoteTTL()
+	if ttl <= 0 {
+		return nil, false
+	}
+	path := filepath.Join(g.cacheDir, name)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		return nil, false
+	}
+	//#nosec G304
+	data, err := os.ReadFile(path)
+	traceFS("read", path, err)
+	return data, err == nil
+}
 
-
+func (g *GoVM) writeRemoteCache(name string, data []byte) {
+	if g.config.RemoteTTL() <= 0 {
+		return
+	}
+	if err := os.MkdirAll(g.cacheDir, os.ModePerm); err != nil {
+		return
+	}
+	path := filepath.Join(g.cacheDir, name)
+	err := os.WriteFile(path, data, 0644)
+	traceFS("write", path, err)
+}
@@ -0,0 +1,75 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInstalledVersions(t *testing.T) {
+	home, projects := t.TempDir(), t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_PROJECT_DIRS", projects)
+	if err := os.WriteFile(filepath.Join(projects, "go.mod"), []byte("module app\n\ngo 1.16\ntoolchain go1.16.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"1.17rc1", "1.16.2", "1.9", "1.16"} {
+		if err := os.MkdirAll(filepath.Join(home, "versions", v, "go", "bin"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	installedAt := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	if err := g.writeReceipt(Receipt{Version: "1.16", Source: "https://golang.org/dl/go1.16.linux-amd64.tar.gz", Mirror: "https://golang.org/dl/", SHA256: "abc", InstalledAt: installedAt}); err != nil {
+		t.Fatal(err)
+	}
+	g.touchVersion("1.9")
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.16")
+
+	versions, err := g.InstalledVersions(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1.9", "1.16", "1.16.2", "1.17rc1"}
+	if len(versions) != len(want) {
+		t.Fatalf("got %d versions, want %d", len(versions), len(want))
+	}
+	for i, v := range versions {
+		if v.Version != want[i] {
+			t.Errorf("versions[%d] = %s, want %s", i, v.Version, want[i])
+		}
+	}
+	if !versions[1].Active || versions[0].Active {
+		t.Errorf("expected only 1.16 to be active")
+	}
+	if !versions[1].InstalledAt.Equal(installedAt) || versions[1].Mirror != "https://golang.org/dl/" {
+		t.Errorf("receipt not read: %+v", versions[1])
+	}
+	if versions[1].SHA256 != "abc" || versions[0].LastUsed == nil || versions[1].LastUsed != nil {
+		t.Errorf("receipt or last use not read: %+v", versions[:2])
+	}
+	if pins := versions[2].PinnedBy; len(pins) != 1 || pins[0] != filepath.Join(projects, "go.mod") {
+		t.Errorf("1.16.2 pinned by %v", pins)
+	}
+	if versions[3].Stability != StabilityPrerelease || versions[2].Stability != StabilityStable {
+		t.Errorf("wrong stability: %+v", versions)
+	}
+}
+
+func TestInstalledVersionsWithoutVersionsDir(t *testing.T) {
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions, err := g.InstalledVersions(false)
+	if err != nil || len(versions) != 0 {
+		t.Fatalf("got %v, %v", versions, err)
+	}
+}
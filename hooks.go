@@ -0,0 +1,36 @@
+package govm
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// runHook runs the shell command configured for the hook name, an empty
+// command is a no-op. GOVM_VERSION is set for the command, and GOVM_HOME
+// unless the XDG layout is used, which GOVM_HOME would override.
+func (g *GoVM) runHook(name, command, version string) (err error) {
+	if command == "" {
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		tracer.Event("hook", "name", name, "command", command, "version", version, "duration", time.Since(start), "error", err)
+	}()
+	DebugT("hook.running", name, command)
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	// #nosec G204
+	cmd := exec.Command(shell, flag, command)
+	cmd.Env = append(os.Environ(), "GOVM_VERSION="+version)
+	if !g.layout.XDG {
+		cmd.Env = append(cmd.Env, "GOVM_HOME="+g.installDir)
+	}
+	cmd.Stdout = reporter.Out
+	cmd.Stderr = reporter.Err
+	return cmd.Run()
+}
@@ -0,0 +1,1566 @@
+package govm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Supported locales
+const (
+	LocaleEN = "en"
+	LocaleZH = "zh-CN"
+)
+
+// Locales lists every locale a message must be translated to
+var Locales = []string{LocaleEN, LocaleZH}
+
+var locale = DetectLocale()
+
+// DetectLocale picks the locale from GOVM_LANG, LC_ALL, LC_MESSAGES and LANG,
+// in that order, falling back to English
+func DetectLocale() string {
+	for _, name := range []string{"GOVM_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return MatchLocale(value)
+		}
+	}
+	return LocaleEN
+}
+
+// MatchLocale maps values such as zh_CN.UTF-8 or en_US to a supported locale
+func MatchLocale(value string) string {
+	if strings.HasPrefix(strings.ToLower(value), "zh") {
+		return LocaleZH
+	}
+	return LocaleEN
+}
+
+// SetLocale selects the locale of every message, unknown locales are matched by MatchLocale
+func SetLocale(l string) {
+	locale = MatchLocale(l)
+}
+
+// CurrentLocale returns the locale messages are printed in
+func CurrentLocale() string {
+	return locale
+}
+
+// Msg returns the format string of key in the current locale,
+// unknown keys are returned as is so a missing message is still visible
+func Msg(key string) string {
+	if translations, ok := messages[key]; ok {
+		if msg, ok := translations[locale]; ok {
+			return msg
+		}
+		return translations[LocaleEN]
+	}
+	return key
+}
+
+// T formats the message key in the current locale
+func T(key string, a ...interface{}) string {
+	if len(a) == 0 {
+		return Msg(key)
+	}
+	return fmt.Sprintf(Msg(key), a...)
+}
+
+// SuccessT prints the message key as a success message
+func SuccessT(key string, a ...interface{}) {
+	reporter.Successf("%s", T(key, a...))
+}
+
+// InfoT prints the message key as a status message
+func InfoT(key string, a ...interface{}) {
+	reporter.Infof("%s", T(key, a...))
+}
+
+// DebugT prints the message key in verbose mode
+func DebugT(key string, a ...interface{}) {
+	reporter.Debugf("%s", T(key, a...))
+}
+
+// ErrorT prints the message key as an error message
+func ErrorT(key string, a ...interface{}) {
+	reporter.Errorf("%s", T(key, a...))
+}
+
+// messages is the catalog of every user-facing message, keyed by message and locale
+var messages = map[string]map[string]string{
+	// library
+	"list.failed": {
+		LocaleEN: "list versions failed",
+		LocaleZH: "列出版本失败",
+	},
+	"remote.fetching": {
+		LocaleEN: "[Info] Fetching remote versions\n",
+		LocaleZH: "[信息] 正在获取远程版本\n",
+	},
+	"remote.stable_prerelease": {
+		LocaleEN: "--stable and --prerelease exclude each other",
+		LocaleZH: "--stable 和 --prerelease 不能同时使用",
+	},
+	"remote.bad_platform": {
+		LocaleEN: "invalid platform %q, expected os/arch, e.g. linux/arm64",
+		LocaleZH: "无效的平台 %q, 格式为 操作系统/架构, 例如 linux/arm64",
+	},
+	"remote.downloads_failed": {
+		LocaleEN: "cannot read the list of published archives",
+		LocaleZH: "无法读取已发布的压缩包列表",
+	},
+	"remote.installed": {
+		LocaleEN: " ✓",
+		LocaleZH: " ✓",
+	},
+	"version.missing": {
+		LocaleEN: "[Error] No version provided\n",
+		LocaleZH: "[错误] 没有提供版本\n",
+	},
+	"uninstall.current": {
+		LocaleEN: "[Error] Version: %s you are trying to remove is your current version. Please use a different version first before uninstalling the current version\n",
+		LocaleZH: "[错误] 要卸载的版本 %s 是当前使用的版本, 请先切换到其他版本再卸载\n",
+	},
+	"uninstall.not_installed": {
+		LocaleEN: "[Error] Version: %s you are trying to remove is not installed\n",
+		LocaleZH: "[错误] 要卸载的版本 %s 没有安装\n",
+	},
+	"uninstall.system_version": {
+		LocaleEN: "[Error] Version: %s is installed in the system store, an administrator can remove it with 'govm --system uninstall'\n",
+		LocaleZH: "[错误] 版本 %s 安装在系统目录中, 需要管理员使用 'govm --system uninstall' 卸载\n",
+	},
+	"uninstall.done": {
+		LocaleEN: "[Success] Version: %s uninstalled\n",
+		LocaleZH: "[成功] 已卸载版本: %s\n",
+	},
+	"install.exists": {
+		LocaleEN: "[Info] Version: %s exists\n",
+		LocaleZH: "[信息] 版本 %s 已经安装\n",
+	},
+	"install.downloading": {
+		LocaleEN: "[Info] Downloading version: %s\n",
+		LocaleZH: "[信息] 正在下载版本: %s\n",
+	},
+	"install.done": {
+		LocaleEN: "[Success] Downloaded version: %s\n",
+		LocaleZH: "[成功] 已下载版本: %s\n",
+	},
+	"install.download_from": {
+		LocaleEN: "[Info] Downloading from: %s\n",
+		LocaleZH: "[信息] 下载地址: %s\n",
+	},
+	"install.download_to": {
+		LocaleEN: "[Info] Downloading to: %s\n",
+		LocaleZH: "[信息] 下载到: %s\n",
+	},
+	"install.download_failed": {
+		LocaleEN: "[Error] Downloading version failed: %s\n",
+		LocaleZH: "[错误] 下载版本失败: %s\n",
+	},
+	"install.check_connectivity": {
+		LocaleEN: "[Error] Please check connectivity to url: %s (run with --debug for a trace)\n",
+		LocaleZH: "[错误] 请检查能否访问: %s (使用 --debug 查看调试日志)\n",
+	},
+	"install.extract_from": {
+		LocaleEN: "[Info] Extracting from: %s\n",
+		LocaleZH: "[信息] 解压文件: %s\n",
+	},
+	"install.extract_to": {
+		LocaleEN: "[Info] Extracting to: %s\n",
+		LocaleZH: "[信息] 解压到: %s\n",
+	},
+	"install.extract_failed": {
+		LocaleEN: "[Error] Untar failed: %s\n",
+		LocaleZH: "[错误] 解压失败: %s\n",
+	},
+	"install.check_version": {
+		LocaleEN: "[Error] Please check if version exists from url: %s\n",
+		LocaleZH: "[错误] 请检查该版本是否存在: %s\n",
+	},
+	"install.extracted": {
+		LocaleEN: "[Success] Untar to %s\n",
+		LocaleZH: "[成功] 已解压到 %s\n",
+	},
+	"install.receipt_failed": {
+		LocaleEN: "[Error] Cannot write install receipt: %s\n",
+		LocaleZH: "[错误] 无法写入安装记录: %s\n",
+	},
+	"install.cached_download": {
+		LocaleEN: "[Info] Using the downloaded archive: %s\n",
+		LocaleZH: "[信息] 使用已下载的文件: %s\n",
+	},
+	"install.trying_mirror": {
+		LocaleEN: "[Info] Trying mirror: %s\n",
+		LocaleZH: "[信息] 尝试镜像: %s\n",
+	},
+	"install.exists_system": {
+		LocaleEN: "[Info] Version: %s is installed in the system store %s\n",
+		LocaleZH: "[信息] 版本 %s 已安装在系统目录 %s\n",
+	},
+	"install.staging_failed": {
+		LocaleEN: "[Error] Cannot create the staging directory: %s\n",
+		LocaleZH: "[错误] 无法创建临时安装目录: %s\n",
+	},
+	"install.validate_failed": {
+		LocaleEN: "[Error] The downloaded go %s does not run, nothing was installed: %s\n",
+		LocaleZH: "[错误] 下载的go %s 无法运行, 没有安装任何文件: %s\n",
+	},
+	"install.version_mismatch": {
+		LocaleEN: "the archive contains %q",
+		LocaleZH: "压缩包中是 %q",
+	},
+	"install.commit_failed": {
+		LocaleEN: "[Error] Cannot move version %s into place: %s\n",
+		LocaleZH: "[错误] 无法将版本 %s 移动到安装目录: %s\n",
+	},
+	"install.locked": {
+		LocaleEN: "version %s is being installed or repaired by govm (pid %d), try again when it is done",
+		LocaleZH: "版本 %s 正在由GoVM安装或修复 (pid %d), 请在完成后重试",
+	},
+	"install.stale_lock": {
+		LocaleEN: "[Info] Taking over the lock of an interrupted install: %s (pid %d)\n",
+		LocaleZH: "[信息] 接管中断的安装留下的锁: %s (pid %d)\n",
+	},
+	"install.stale_staging": {
+		LocaleEN: "[Info] Removing the staging directory of an interrupted install: %s\n",
+		LocaleZH: "[信息] 删除中断的安装留下的临时目录: %s\n",
+	},
+	"install.manifest_failed": {
+		LocaleEN: "[Error] Cannot record the file manifest, govm verify cannot check this version: %s\n",
+		LocaleZH: "[错误] 无法记录文件清单, govm verify 无法检查该版本: %s\n",
+	},
+	"install.chmod_failed": {
+		LocaleEN: "[Error] Cannot make the version readable by every user: %s\n",
+		LocaleZH: "[错误] 无法让所有用户读取该版本: %s\n",
+	},
+	"system.not_writable": {
+		LocaleEN: "cannot write to the system store %s, run as root or administrator",
+		LocaleZH: "无法写入系统目录 %s, 请以root或管理员身份运行",
+	},
+	"hook.running": {
+		LocaleEN: "[Info] Running %s hook: %s\n",
+		LocaleZH: "[信息] 运行 %s 钩子: %s\n",
+	},
+	"hook.failed": {
+		LocaleEN: "[Error] The %s hook failed: %s\n",
+		LocaleZH: "[错误] %s 钩子运行失败: %s\n",
+	},
+	"use.already": {
+		LocaleEN: "[Info] Version: %s is already your current version\n",
+		LocaleZH: "[信息] 版本 %s 已经是当前使用的版本\n",
+	},
+	"use.changing": {
+		LocaleEN: "[Info] Changing go version to: %s\n",
+		LocaleZH: "[信息] 正在切换go版本到: %s\n",
+	},
+	"use.done": {
+		LocaleEN: "[Success] Changed go version to: %s\n",
+		LocaleZH: "[成功] 已切换go版本到: %s\n",
+	},
+	"use.symlink_failed": {
+		LocaleEN: "[Error] Symbolic link failed: %s\n",
+		LocaleZH: "[错误] 创建符号链接失败: %s\n",
+	},
+	"upgrade.newest": {
+		LocaleEN: "[Info] Your version is already the newest\n",
+		LocaleZH: "[信息] 当前已经是最新版本\n",
+	},
+	"upgrade.available": {
+		LocaleEN: "[Info] GoVM %s is available, you are using %s\n",
+		LocaleZH: "[信息] GoVM有新版本 %s, 当前版本 %s\n",
+	},
+	"upgrade.already": {
+		LocaleEN: "[Info] GoVM %s is already installed\n",
+		LocaleZH: "[信息] 已经是GoVM %s\n",
+	},
+	"upgrade.verifying": {
+		LocaleEN: "[Info] Verifying the checksums of GoVM %s\n",
+		LocaleZH: "[信息] 正在校验GoVM %s 的签名\n",
+	},
+	"upgrade.downloading": {
+		LocaleEN: "[Info] Downloading GoVM %s\n",
+		LocaleZH: "[信息] 正在下载GoVM %s\n",
+	},
+	"upgrade.download_failed": {
+		LocaleEN: "download GoVM failed: %s",
+		LocaleZH: "下载GoVM失败: %s",
+	},
+	"upgrade.chmod_failed": {
+		LocaleEN: "cannot set file as executable: %s",
+		LocaleZH: "无法设置可执行权限: %s",
+	},
+	"upgrade.checksum_mismatch": {
+		LocaleEN: "checksum mismatch for %s: got %s, want %s",
+		LocaleZH: "%s 校验和不匹配: 实际 %s, 应为 %s",
+	},
+	"upgrade.rolled_back": {
+		LocaleEN: "the new binary does not run, the previous one was restored: %s",
+		LocaleZH: "新版本无法运行, 已恢复原来的版本: %s",
+	},
+	"upgrade.no_key": {
+		LocaleEN: "this build of GoVM has no release key and cannot verify updates, please reinstall it with install.sh",
+		LocaleZH: "当前GoVM构建没有发布公钥, 无法校验更新, 请使用install.sh重新安装",
+	},
+	"upgrade.bad_key": {
+		LocaleEN: "the release key of this build is not a base64 encoded ed25519 public key",
+		LocaleZH: "当前构建的发布公钥不是base64编码的ed25519公钥",
+	},
+	"upgrade.bad_signature": {
+		LocaleEN: "the signature of the release checksums is invalid",
+		LocaleZH: "发布校验和文件的签名无效",
+	},
+	"upgrade.no_checksum": {
+		LocaleEN: "the release checksums do not list %s",
+		LocaleZH: "发布校验和文件中没有 %s",
+	},
+	"upgrade.no_release": {
+		LocaleEN: "cannot read the latest GoVM release, the GitHub rate limit may be exceeded",
+		LocaleZH: "无法获取GoVM最新版本, 可能超出了GitHub访问频率限制",
+	},
+	"upgrade.bad_channel": {
+		LocaleEN: "unknown release channel %s, use stable or prerelease",
+		LocaleZH: "未知的发布渠道 %s, 请使用 stable 或 prerelease",
+	},
+	"notice.govm": {
+		LocaleEN: "[Info] GoVM %s is available on the %s channel, run 'govm self-update' to upgrade\n",
+		LocaleZH: "[信息] GoVM %s 已在 %s 渠道发布, 运行 'govm self-update' 升级\n",
+	},
+	"notice.go": {
+		LocaleEN: "[Info] Go %s is available, you are using %s, run 'govm use %s' to switch\n",
+		LocaleZH: "[信息] Go %s 已发布, 当前使用 %s, 运行 'govm use %s' 切换\n",
+	},
+	"upgrade.done": {
+		LocaleEN: "[Success] Upgraded GoVM to %s\n",
+		LocaleZH: "[成功] GoVM已升级到 %s\n",
+	},
+	"tags.request_failed": {
+		LocaleEN: "[Error] Cannot create request: %s\n",
+		LocaleZH: "[错误] 无法创建请求: %s\n",
+	},
+	"tags.response_failed": {
+		LocaleEN: "[Error] Cannot get response: %s\n",
+		LocaleZH: "[错误] 无法获取响应: %s\n",
+	},
+	"tags.read_failed": {
+		LocaleEN: "[Error] Cannot read response: %s\n",
+		LocaleZH: "[错误] 无法读取响应: %s\n",
+	},
+	"tags.rate_limit": {
+		LocaleEN: "[Error] Rate limit exceeded\n",
+		LocaleZH: "[错误] 超出访问频率限制\n",
+	},
+	"download.bad_status": {
+		LocaleEN: "unexpected response %s from %s",
+		LocaleZH: "%[2]s 返回了异常响应 %[1]s",
+	},
+	"trace.unknown_option": {
+		LocaleEN: "unknown GOVM_LOG option: %s",
+		LocaleZH: "未知的GOVM_LOG选项: %s",
+	},
+	"trace.unknown_format": {
+		LocaleEN: "unknown log format: %s",
+		LocaleZH: "未知的日志格式: %s",
+	},
+	"trace.written": {
+		LocaleEN: "[Info] Debug log written to: %s\n",
+		LocaleZH: "[信息] 调试日志已写入: %s\n",
+	},
+	"config.parse_failed": {
+		LocaleEN: "cannot read config %s: %s",
+		LocaleZH: "无法读取配置文件 %s: %s",
+	},
+	"config.unknown_key_in_file": {
+		LocaleEN: "config %s: unknown key %s",
+		LocaleZH: "配置文件 %s: 未知的配置项 %s",
+	},
+	"config.unknown_key": {
+		LocaleEN: "unknown config key: %s (run 'govm config list' for all keys)",
+		LocaleZH: "未知的配置项: %s (运行 'govm config list' 查看所有配置项)",
+	},
+	"config.bad_env": {
+		LocaleEN: "%s: %s",
+		LocaleZH: "%s: %s",
+	},
+	"config.bad_choice": {
+		LocaleEN: "%s must be one of %s, got %q",
+		LocaleZH: "%s 只能是 %s 之一, 而不是 %q",
+	},
+	"config.bad_duration": {
+		LocaleEN: "%s must be a duration such as 1h or 30m, got %q",
+		LocaleZH: "%s 必须是 1h、30m 这样的时长, 而不是 %q",
+	},
+	"config.bad_parallelism": {
+		LocaleEN: "parallelism must be at least 1, got %d",
+		LocaleZH: "parallelism 至少为1, 而不是 %d",
+	},
+	"config.bad_selector": {
+		LocaleEN: "%s: %s",
+		LocaleZH: "%s: %s",
+	},
+	"config.bad_url": {
+		LocaleEN: "%s must be an absolute URL, got %q",
+		LocaleZH: "%s 必须是完整的URL, 而不是 %q",
+	},
+	"config.bad_path": {
+		LocaleEN: "%s must be an absolute path, got %q",
+		LocaleZH: "%s 必须是绝对路径, 而不是 %q",
+	},
+	"config.bad_bool": {
+		LocaleEN: "%s must be true or false, got %q",
+		LocaleZH: "%s 只能是 true 或 false, 而不是 %q",
+	},
+	"config.bad_int": {
+		LocaleEN: "%s must be a number, got %q",
+		LocaleZH: "%s 必须是数字, 而不是 %q",
+	},
+	"config.unknown_action": {
+		LocaleEN: "unknown config action: %s (use list, get or set)",
+		LocaleZH: "未知的config操作: %s (可用 list、get 或 set)",
+	},
+	"config.set": {
+		LocaleEN: "[Success] Set %s = %s in %s\n",
+		LocaleZH: "[成功] 已在 %[3]s 中设置 %[1]s = %[2]s\n",
+	},
+	"config.env_overrides": {
+		LocaleEN: "[Info] %s is set and overrides %s\n",
+		LocaleZH: "[信息] 环境变量 %s 已设置, 会覆盖 %s\n",
+	},
+	"config.source.default": {
+		LocaleEN: "(default)",
+		LocaleZH: "(默认)",
+	},
+	"config.source.file": {
+		LocaleEN: "(config file)",
+		LocaleZH: "(配置文件)",
+	},
+	"config.source.env": {
+		LocaleEN: "(%s)",
+		LocaleZH: "(%s)",
+	},
+	"layout.no_home": {
+		LocaleEN: "cannot find the home directory, set GOVM_HOME to the directory GoVM should use",
+		LocaleZH: "找不到用户主目录, 请将GOVM_HOME设置为GoVM使用的目录",
+	},
+	"migrate.same": {
+		LocaleEN: "GoVM already uses %s",
+		LocaleZH: "GoVM已经在使用 %s",
+	},
+	"migrate.system": {
+		LocaleEN: "the system store cannot be migrated, move it and set system.store instead",
+		LocaleZH: "不能迁移系统目录, 请移动后修改配置项 system.store",
+	},
+	"migrate.exists": {
+		LocaleEN: "%s already exists, nothing was moved",
+		LocaleZH: "%s 已存在, 没有移动任何文件",
+	},
+	"migrate.moving": {
+		LocaleEN: "[Info] Moving %s to %s\n",
+		LocaleZH: "[信息] 移动 %s 到 %s\n",
+	},
+	"migrate.failed": {
+		LocaleEN: "cannot move %s: %s",
+		LocaleZH: "无法移动 %s: %s",
+	},
+	"migrate.leftover": {
+		LocaleEN: "[Info] %s still contains files that are not GoVM's, remove it once they are no longer needed\n",
+		LocaleZH: "[信息] %s 中还有不属于GoVM的文件, 不再需要后请删除该目录\n",
+	},
+	"migrate.done": {
+		LocaleEN: "[Success] Moved GoVM to %s\n",
+		LocaleZH: "[成功] GoVM已移动到 %s\n",
+	},
+	"migrate.set_home": {
+		LocaleEN: "[Info] Add this to your shell profile: export GOVM_HOME=\"%s\"\n",
+		LocaleZH: "[信息] 请在shell配置文件中添加: export GOVM_HOME=\"%s\"\n",
+	},
+	"migrate.unset_home": {
+		LocaleEN: "[Info] Remove GOVM_HOME from your shell profile so the XDG dirs are used\n",
+		LocaleZH: "[信息] 请从shell配置文件中删除GOVM_HOME, 以使用XDG目录\n",
+	},
+	"migrate.path_hint": {
+		LocaleEN: "[Info] Update PATH in your shell profile: export PATH=\"%s:%s:$PATH\"\n",
+		LocaleZH: "[信息] 请更新shell配置文件中的PATH: export PATH=\"%s:%s:$PATH\"\n",
+	},
+	"store.dedupe_failed": {
+		LocaleEN: "[Error] Cannot link version %s into the store, it keeps its own files: %s\n",
+		LocaleZH: "[错误] 无法将版本 %s 链接到存储, 该版本保留自己的文件: %s\n",
+	},
+	"store.gc": {
+		LocaleEN: "[Info] Removed %d unused files from the store, freed %s\n",
+		LocaleZH: "[信息] 已从存储中删除 %d 个不再使用的文件, 释放 %s\n",
+	},
+	"store.gc_failed": {
+		LocaleEN: "[Error] Cannot clean the store: %s\n",
+		LocaleZH: "[错误] 无法清理存储: %s\n",
+	},
+	"store.unknown_action": {
+		LocaleEN: "unknown store action %q, use stats or gc",
+		LocaleZH: "未知的store操作 %q, 请使用 stats 或 gc",
+	},
+	"store.stats.dir": {
+		LocaleEN: "store:     %s\n",
+		LocaleZH: "存储目录:   %s\n",
+	},
+	"store.stats.versions": {
+		LocaleEN: "versions:  %d\n",
+		LocaleZH: "版本数:     %d\n",
+	},
+	"store.stats.blobs": {
+		LocaleEN: "files:     %d (%s)\n",
+		LocaleZH: "文件数:     %d (%s)\n",
+	},
+	"store.stats.linked": {
+		LocaleEN: "linked:    %s\n",
+		LocaleZH: "链接大小:   %s\n",
+	},
+	"store.stats.saved": {
+		LocaleEN: "saved:     %s\n",
+		LocaleZH: "节省空间:   %s\n",
+	},
+	"store.stats.unreferenced": {
+		LocaleEN: "unused:    %d files, run govm store gc to remove them\n",
+		LocaleZH: "未使用:     %d 个文件, 运行 govm store gc 删除\n",
+	},
+	"selector.empty": {
+		LocaleEN: "no version selector given",
+		LocaleZH: "没有指定版本",
+	},
+	"version.invalid": {
+		LocaleEN: "invalid Go version %q",
+		LocaleZH: "无效的 Go 版本 %q",
+	},
+	"selector.invalid": {
+		LocaleEN: "invalid version selector %q: cannot parse %q",
+		LocaleZH: "无效的版本选择 %q: 无法解析 %q",
+	},
+	"selector.no_match": {
+		LocaleEN: "no published version matches %q, run govm ls-remote to see them",
+		LocaleZH: "没有已发布的版本符合 %q, 运行 govm ls-remote 查看所有版本",
+	},
+	"explain.version": {
+		LocaleEN: "version:    %s\n",
+		LocaleZH: "版本:       %s\n",
+	},
+	"explain.not_installed": {
+		LocaleEN: "%s (not installed)",
+		LocaleZH: "%s (未安装)",
+	},
+	"explain.none": {
+		LocaleEN: "none",
+		LocaleZH: "无",
+	},
+	"explain.selector": {
+		LocaleEN: "selector:   %s\n",
+		LocaleZH: "版本选择:   %s\n",
+	},
+	"explain.origin.env": {
+		LocaleEN: "source:     environment variable %s\n",
+		LocaleZH: "来源:       环境变量 %s\n",
+	},
+	"explain.origin.pin_file": {
+		LocaleEN: "source:     pin file %s\n",
+		LocaleZH: "来源:       固定文件 %s\n",
+	},
+	"explain.origin.go_mod": {
+		LocaleEN: "source:     toolchain line of %s\n",
+		LocaleZH: "来源:       %s 的toolchain行\n",
+	},
+	"explain.origin.global": {
+		LocaleEN: "source:     global version set by govm use\n",
+		LocaleZH: "来源:       govm use 设置的全局版本\n",
+	},
+	"explain.binary": {
+		LocaleEN: "binary:     %s\n",
+		LocaleZH: "可执行文件: %s\n",
+	},
+	"explain.global": {
+		LocaleEN: "global:     %s\n",
+		LocaleZH: "全局版本:   %s\n",
+	},
+	"explain.path_go": {
+		LocaleEN: "PATH go:    %s\n",
+		LocaleZH: "PATH中的go: %s\n",
+	},
+	"explain.shadowed": {
+		LocaleEN: "[Info] %s comes before %s on PATH and runs instead of govm's go\n",
+		LocaleZH: "[信息] PATH中 %s 在 %s 之前, 运行的不是GoVM的go\n",
+	},
+	"explain.not_on_path": {
+		LocaleEN: "[Info] %s is not on PATH, add it to run govm's go\n",
+		LocaleZH: "[信息] %s 不在PATH中, 添加后才能运行GoVM的go\n",
+	},
+	"explain.not_global": {
+		LocaleEN: "[Info] go on PATH runs the global version %s, run 'govm use' here or 'govm exec %s -- go' to use %s\n",
+		LocaleZH: "[信息] PATH中的go是全局版本 %s, 在此运行 'govm use' 或运行 'govm exec %s -- go' 来使用 %s\n",
+	},
+	"which.no_match": {
+		LocaleEN: "no installed version matches %s, run 'govm install %s'",
+		LocaleZH: "没有已安装的版本符合 %s, 请运行 'govm install %s'",
+	},
+	"which.none": {
+		LocaleEN: "no Go version is selected, run govm use first",
+		LocaleZH: "没有选择Go版本, 请先运行 govm use",
+	},
+	"doctor.ok": {
+		LocaleEN: "[Success] No problems found\n",
+		LocaleZH: "[成功] 没有发现问题\n",
+	},
+	"doctor.error": {
+		LocaleEN: "[Error] %s\n",
+		LocaleZH: "[错误] %s\n",
+	},
+	"doctor.warning": {
+		LocaleEN: "[Info] %s\n",
+		LocaleZH: "[信息] %s\n",
+	},
+	"doctor.suggestion": {
+		LocaleEN: "    fix: %s\n",
+		LocaleZH: "    修复: %s\n",
+	},
+	"doctor.fixed": {
+		LocaleEN: "[Success] Fixed: %s\n",
+		LocaleZH: "[成功] 已修复: %s\n",
+	},
+	"doctor.failed": {
+		LocaleEN: "%d problems need fixing",
+		LocaleZH: "%d 个问题需要处理",
+	},
+	"doctor.path.missing": {
+		LocaleEN: "%s is not on PATH",
+		LocaleZH: "%s 不在PATH中",
+	},
+	"doctor.path.missing_fix": {
+		LocaleEN: "add export PATH=\"%s:$PATH\" to your shell profile",
+		LocaleZH: "在shell配置文件中添加 export PATH=\"%s:$PATH\"",
+	},
+	"doctor.path.shadowed": {
+		LocaleEN: "%s comes before %s on PATH and runs instead of govm's go",
+		LocaleZH: "PATH中 %s 在 %s 之前, 运行的不是GoVM的go",
+	},
+	"doctor.path.shadowed_fix": {
+		LocaleEN: "put %s in front of %s on PATH",
+		LocaleZH: "在PATH中把 %s 放在 %s 前面",
+	},
+	"doctor.system_go": {
+		LocaleEN: "Go installed outside govm at %s",
+		LocaleZH: "GoVM之外安装的Go: %s",
+	},
+	"doctor.system_go_fix": {
+		LocaleEN: "bring it under govm with 'govm import --mode move %s', or keep %s off PATH",
+		LocaleZH: "运行 'govm import --mode move %s' 交给GoVM管理, 或者不要把 %s 加入PATH",
+	},
+	"doctor.goroot": {
+		LocaleEN: "GOROOT is set to %s and overrides the version govm selects",
+		LocaleZH: "设置了GOROOT=%s, 会覆盖GoVM选择的版本",
+	},
+	"doctor.goroot_fix": {
+		LocaleEN: "remove GOROOT from your environment and shell profile",
+		LocaleZH: "从环境变量和shell配置文件中删除GOROOT",
+	},
+	"doctor.current_link": {
+		LocaleEN: "%s links to %s, which does not exist",
+		LocaleZH: "%s 指向不存在的 %s",
+	},
+	"doctor.current_link_fix": {
+		LocaleEN: "run govm doctor --fix to remove the link, then govm use <version>",
+		LocaleZH: "运行 govm doctor --fix 删除该链接, 再运行 govm use <版本>",
+	},
+	"doctor.half_installed": {
+		LocaleEN: "version %s in %s has no go command, its install did not finish",
+		LocaleZH: "版本 %s (%s) 没有go命令, 安装没有完成",
+	},
+	"doctor.half_installed_fix": {
+		LocaleEN: "run govm doctor --fix to remove it, then govm install %s",
+		LocaleZH: "运行 govm doctor --fix 删除它, 再运行 govm install %s",
+	},
+	"doctor.stale_staging": {
+		LocaleEN: "%s was left by an interrupted install or repair",
+		LocaleZH: "%s 是被中断的安装或修复留下的",
+	},
+	"doctor.stale_lock": {
+		LocaleEN: "%s is held by govm process %d, which is gone",
+		LocaleZH: "%s 被已经退出的GoVM进程 %d 持有",
+	},
+	"doctor.stale_lock_fix": {
+		LocaleEN: "run govm doctor --fix to remove it",
+		LocaleZH: "运行 govm doctor --fix 删除",
+	},
+	"doctor.stale_staging_fix": {
+		LocaleEN: "run govm doctor --fix to clean it up",
+		LocaleZH: "运行 govm doctor --fix 清理",
+	},
+	"doctor.registry": {
+		LocaleEN: "the registry %s cannot be reached: %v",
+		LocaleZH: "无法访问镜像 %s: %v",
+	},
+	"doctor.registry_fix": {
+		LocaleEN: "check the network and network.proxy, or set another mirror.registry",
+		LocaleZH: "检查网络和network.proxy配置, 或者换一个mirror.registry",
+	},
+	"doctor.gobrew_path": {
+		LocaleEN: "PATH still contains the gobrew directory %s",
+		LocaleZH: "PATH中仍有gobrew的目录 %s",
+	},
+	"doctor.gobrew_path_fix": {
+		LocaleEN: "remove the .gobrew entries from PATH in your shell profile",
+		LocaleZH: "从shell配置文件的PATH中删除.gobrew相关的目录",
+	},
+	"doctor.gobrew_dir": {
+		LocaleEN: "gobrew left its files in %s",
+		LocaleZH: "gobrew 的文件仍在 %s",
+	},
+	"doctor.gobrew_dir_fix": {
+		LocaleEN: "remove %s when you no longer need it",
+		LocaleZH: "不再需要时删除 %s",
+	},
+	"uninstall.linked": {
+		LocaleEN: "[Info] Removed the link only, the Go installation in %s is kept\n",
+		LocaleZH: "[信息] 只删除了链接, %s 中的Go保持不变\n",
+	},
+	"import.bad_mode": {
+		LocaleEN: "unknown import mode %q, use copy, move or link",
+		LocaleZH: "未知的导入方式 %q, 可以使用 copy、move 或 link",
+	},
+	"import.not_goroot": {
+		LocaleEN: "%s is neither a Go installation nor its go command",
+		LocaleZH: "%s 不是Go的安装目录或go命令",
+	},
+	"import.unknown_version": {
+		LocaleEN: "cannot tell the Go version of %s from %q",
+		LocaleZH: "无法从 %[2]q 识别 %[1]s 的Go版本",
+	},
+	"import.managed": {
+		LocaleEN: "%s is already managed by govm",
+		LocaleZH: "%s 已经由GoVM管理",
+	},
+	"import.exists": {
+		LocaleEN: "version %s is already installed",
+		LocaleZH: "版本 %s 已经安装",
+	},
+	"import.restore_failed": {
+		LocaleEN: "cannot import %s: %v, and cannot move it back: %v, the Go installation is kept in %s",
+		LocaleZH: "无法导入 %s: %v, 也无法移回原处: %v, 该Go安装保留在 %s",
+	},
+	"import.failed": {
+		LocaleEN: "cannot import %s: %v",
+		LocaleZH: "无法导入 %s: %v",
+	},
+	"import.done": {
+		LocaleEN: "[Success] Imported Go %s from %s (%s)\n",
+		LocaleZH: "[成功] 已从 %[2]s 导入Go %[1]s (%[3]s)\n",
+	},
+	"import.none": {
+		LocaleEN: "[Info] No Go installations found outside govm\n",
+		LocaleZH: "[信息] 没有找到GoVM之外的Go安装\n",
+	},
+	"import.candidate": {
+		LocaleEN: "%-12s %s\n",
+		LocaleZH: "%-12s %s\n",
+	},
+	"import.candidate_installed": {
+		LocaleEN: "%-12s %s (already installed)\n",
+		LocaleZH: "%-12s %s (已安装)\n",
+	},
+	"alias.bad_name": {
+		LocaleEN: "invalid alias name %q: use letters, digits, '.', '-' and '_', starting with a letter",
+		LocaleZH: "无效的别名 %q: 只能使用字母、数字、'.'、'-' 和 '_', 并以字母开头",
+	},
+	"alias.selector_name": {
+		LocaleEN: "%q is a version selector and cannot be an alias",
+		LocaleZH: "%q 是版本选择写法, 不能用作别名",
+	},
+	"alias.unknown": {
+		LocaleEN: "no alias named %q",
+		LocaleZH: "没有名为 %q 的别名",
+	},
+	"alias.unknown_action": {
+		LocaleEN: "unknown alias action %q, use list, set, rm or update",
+		LocaleZH: "未知的alias操作 %q, 可以使用 list、set、rm 或 update",
+	},
+	"alias.set": {
+		LocaleEN: "[Success] %s -> %s\n",
+		LocaleZH: "[成功] %s -> %s\n",
+	},
+	"alias.removed": {
+		LocaleEN: "[Success] Removed alias %s\n",
+		LocaleZH: "[成功] 已删除别名 %s\n",
+	},
+	"alias.updated": {
+		LocaleEN: "[Success] %s -> %s (%s)\n",
+		LocaleZH: "[成功] %s -> %s (%s)\n",
+	},
+	"alias.up_to_date": {
+		LocaleEN: "[Info] Aliases are up to date\n",
+		LocaleZH: "[信息] 别名都已是最新\n",
+	},
+	"alias.line": {
+		LocaleEN: "%-12s %s\n",
+		LocaleZH: "%-12s %s\n",
+	},
+	"alias.line_selector": {
+		LocaleEN: "%-12s %s (%s)\n",
+		LocaleZH: "%-12s %s (%s)\n",
+	},
+	"alias.none": {
+		LocaleEN: "[Info] No aliases, add one with govm alias set <name> <version>\n",
+		LocaleZH: "[信息] 没有别名, 使用 govm alias set <名称> <版本> 添加\n",
+	},
+	"upgrade_go.none": {
+		LocaleEN: "[Info] Every installed minor version is on its latest patch\n",
+		LocaleZH: "[信息] 已安装的次版本都是最新的补丁版本\n",
+	},
+	"upgrade_go.step": {
+		LocaleEN: "%s: %s -> %s\n",
+		LocaleZH: "%s: %s -> %s\n",
+	},
+	"upgrade_go.current": {
+		LocaleEN: "    switch the current version to %s\n",
+		LocaleZH: "    当前版本切换到 %s\n",
+	},
+	"upgrade_go.aliases": {
+		LocaleEN: "    move the aliases %s\n",
+		LocaleZH: "    移动别名 %s\n",
+	},
+	"upgrade_go.prune": {
+		LocaleEN: "    remove %s\n",
+		LocaleZH: "    删除 %s\n",
+	},
+	"upgrade_go.install_failed": {
+		LocaleEN: "cannot install version %s, nothing was switched or removed for it: %s",
+		LocaleZH: "无法安装版本 %s, 没有为其切换或删除任何版本: %s",
+	},
+	"upgrade_go.dry_run": {
+		LocaleEN: "[Info] Nothing was changed, run again without --dry-run to upgrade\n",
+		LocaleZH: "[信息] 没有做任何修改, 去掉 --dry-run 重新运行以升级\n",
+	},
+	"audit.fetching": {
+		LocaleEN: "[Info] Fetching the Go vulnerability database from %s\n",
+		LocaleZH: "[信息] 正在从 %s 下载Go漏洞数据库\n",
+	},
+	"audit.fetch_failed": {
+		LocaleEN: "cannot download the Go vulnerability database",
+		LocaleZH: "无法下载Go漏洞数据库",
+	},
+	"audit.db_invalid": {
+		LocaleEN: "cannot read the vulnerability database %s",
+		LocaleZH: "无法读取漏洞数据库 %s",
+	},
+	"audit.db_format": {
+		LocaleEN: "neither a vulndb.zip nor a JSON file of OSV entries",
+		LocaleZH: "既不是vulndb.zip也不是OSV条目的JSON文件",
+	},
+	"audit.supported": {
+		LocaleEN: "supported minor versions: %s\n",
+		LocaleZH: "仍受支持的次版本: %s\n",
+	},
+	"audit.supported_unknown": {
+		LocaleEN: "supported minor versions: unknown, end of support was not checked\n",
+		LocaleZH: "仍受支持的次版本: 未知, 未检查是否已停止支持\n",
+	},
+	"audit.vulndb": {
+		LocaleEN: "vulnerability database: %s, fetched %s, %d entries\n",
+		LocaleZH: "漏洞数据库: %s, 获取于 %s, 共 %d 条\n",
+	},
+	"audit.no_vulndb": {
+		LocaleEN: "vulnerability database: none, run govm audit --refresh or --db <file>\n",
+		LocaleZH: "漏洞数据库: 无, 请运行 govm audit --refresh 或 --db <文件>\n",
+	},
+	"audit.version_ok": {
+		LocaleEN: "%s: ok\n",
+		LocaleZH: "%s: 正常\n",
+	},
+	"audit.version": {
+		LocaleEN: "%s:\n",
+		LocaleZH: "%s:\n",
+	},
+	"audit.eol": {
+		LocaleEN: "    past end of support\n",
+		LocaleZH: "    已停止支持\n",
+	},
+	"audit.vuln": {
+		LocaleEN: "    %s %s, fixed in %s\n",
+		LocaleZH: "    %s %s, 已在 %s 中修复\n",
+	},
+	"audit.vuln_unfixed": {
+		LocaleEN: "    %s %s, no fix released\n",
+		LocaleZH: "    %s %s, 尚未发布修复\n",
+	},
+	"audit.ok": {
+		LocaleEN: "[Success] No installed version is past end of support or has known vulnerabilities\n",
+		LocaleZH: "[成功] 没有已停止支持或存在已知漏洞的已安装版本\n",
+	},
+	"audit.failed": {
+		LocaleEN: "%d installed versions are past end of support or have known vulnerabilities",
+		LocaleZH: "%d 个已安装的版本已停止支持或存在已知漏洞",
+	},
+	"notes.fetching": {
+		LocaleEN: "[Info] Fetching the release history from %s\n",
+		LocaleZH: "[信息] 正在从 %s 获取发布历史\n",
+	},
+	"notes.read_failed": {
+		LocaleEN: "cannot read the release history %s",
+		LocaleZH: "无法读取发布历史 %s",
+	},
+	"notes.empty": {
+		LocaleEN: "the release history lists no releases",
+		LocaleZH: "发布历史中没有任何版本",
+	},
+	"notes.unknown": {
+		LocaleEN: "version %s was not published",
+		LocaleZH: "版本 %s 不存在",
+	},
+	"notes.no_entry": {
+		LocaleEN: "the release history has no entry for %s",
+		LocaleZH: "发布历史中没有 %s 的记录",
+	},
+	"notes.bad_range": {
+		LocaleEN: "%s: the first version must be older than the second",
+		LocaleZH: "%s: 第一个版本必须早于第二个版本",
+	},
+	"notes.none_in_range": {
+		LocaleEN: "the release history has no entry in %s",
+		LocaleZH: "发布历史中没有 %s 范围内的记录",
+	},
+	"notes.header": {
+		LocaleEN: "%s (released %s)",
+		LocaleZH: "%s (发布于 %s)",
+	},
+	"notes.security": {
+		LocaleEN: " [security]",
+		LocaleZH: " [安全修复]",
+	},
+	"notes.text": {
+		LocaleEN: "    %s\n",
+		LocaleZH: "    %s\n",
+	},
+	"notes.issues": {
+		LocaleEN: "    issues: %s\n",
+		LocaleZH: "    问题列表: %s\n",
+	},
+	"exec.not_installed": {
+		LocaleEN: "version %s is not installed, run govm install first",
+		LocaleZH: "版本 %s 没有安装, 请先运行 govm install",
+	},
+	"prune.no_policy": {
+		LocaleEN: "no prune policy given, use --keep-latest-patch, --keep-recent, --keep-pinned or --prerelease-older-than",
+		LocaleZH: "没有指定清理策略, 请使用 --keep-latest-patch、--keep-recent、--keep-pinned 或 --prerelease-older-than",
+	},
+	"prune.bad_age": {
+		LocaleEN: "invalid age %q, use e.g. 30d or 720h",
+		LocaleZH: "无效的时长 %q, 请使用如 30d 或 720h",
+	},
+	"prune.nothing": {
+		LocaleEN: "no installed versions\n",
+		LocaleZH: "没有已安装的版本\n",
+	},
+	"prune.remove": {
+		LocaleEN: "remove",
+		LocaleZH: "删除",
+	},
+	"prune.keep": {
+		LocaleEN: "keep  ",
+		LocaleZH: "保留",
+	},
+	"prune.reason.current": {
+		LocaleEN: "current version",
+		LocaleZH: "当前使用的版本",
+	},
+	"prune.reason.pinned": {
+		LocaleEN: "pinned by %s",
+		LocaleZH: "被 %s 固定",
+	},
+	"prune.reason.aliased": {
+		LocaleEN: "version of the alias %s",
+		LocaleZH: "别名 %s 的版本",
+	},
+	"prune.reason.latest_patch": {
+		LocaleEN: "latest patch of its minor version",
+		LocaleZH: "该次版本的最新补丁版本",
+	},
+	"prune.reason.recent": {
+		LocaleEN: "recently used",
+		LocaleZH: "最近使用过",
+	},
+	"prune.reason.old_prerelease": {
+		LocaleEN: "prerelease not used recently",
+		LocaleZH: "很久没有使用的预发布版本",
+	},
+	"prune.reason.not_kept": {
+		LocaleEN: "not kept by any policy",
+		LocaleZH: "不被任何策略保留",
+	},
+	"prune.reason.superseded": {
+		LocaleEN: "superseded by a newer patch",
+		LocaleZH: "已被更新的补丁版本取代",
+	},
+	"prune.dry_run": {
+		LocaleEN: "[Info] Nothing was removed, run again with --yes to remove the versions above\n",
+		LocaleZH: "[信息] 没有删除任何版本, 使用 --yes 重新运行以删除上面的版本\n",
+	},
+	"prune.removed": {
+		LocaleEN: "[Success] Version: %s removed\n",
+		LocaleZH: "[成功] 已删除版本: %s\n",
+	},
+	"verify.ok": {
+		LocaleEN: "%s: ok\n",
+		LocaleZH: "%s: 完好\n",
+	},
+	"verify.damaged": {
+		LocaleEN: "%s: %d missing, %d modified, %d extra files\n",
+		LocaleZH: "%s: %d 个文件缺失, %d 个文件被修改, %d 个多余文件\n",
+	},
+	"verify.no_manifest": {
+		LocaleEN: "%s: no manifest, run govm repair %s to record one\n",
+		LocaleZH: "%s: 没有文件清单, 运行 govm repair %s 以记录清单\n",
+	},
+	"verify.missing": {
+		LocaleEN: "missing ",
+		LocaleZH: "缺失",
+	},
+	"verify.modified": {
+		LocaleEN: "modified",
+		LocaleZH: "修改",
+	},
+	"verify.extra": {
+		LocaleEN: "extra   ",
+		LocaleZH: "多余",
+	},
+	"verify.more": {
+		LocaleEN: "    ... and %d more, use --verbose to list all\n",
+		LocaleZH: "    ... 还有 %d 个, 使用 --verbose 列出全部\n",
+	},
+	"verify.failed": {
+		LocaleEN: "%d versions are damaged, fix them with govm repair <version>",
+		LocaleZH: "%d 个版本已损坏, 使用 govm repair <版本> 修复",
+	},
+	"repair.linked": {
+		LocaleEN: "version %s links to an imported installation, repair it there or import it again",
+		LocaleZH: "版本 %s 链接到导入的安装, 请在原位置修复或重新导入",
+	},
+	"repair.system_version": {
+		LocaleEN: "version %s is installed in the system store, an administrator can repair it with 'govm --system repair'",
+		LocaleZH: "版本 %s 安装在系统目录中, 需要管理员使用 'govm --system repair' 修复",
+	},
+	"repair.reinstalling": {
+		LocaleEN: "[Info] Reinstalling version: %s\n",
+		LocaleZH: "[信息] 正在重新安装版本: %s\n",
+	},
+	"repair.failed": {
+		LocaleEN: "cannot reinstall version %s, the previous files were kept: %s",
+		LocaleZH: "无法重新安装版本 %s, 已保留原来的文件: %s",
+	},
+	"repair.done": {
+		LocaleEN: "[Success] Version: %s repaired\n",
+		LocaleZH: "[成功] 已修复版本: %s\n",
+	},
+	"list.current": {
+		LocaleEN: "current: %s\n",
+		LocaleZH: "当前版本: %s\n",
+	},
+	"list.long.path": {
+		LocaleEN: "    path:       %s\n",
+		LocaleZH: "    路径:       %s\n",
+	},
+	"list.long.size": {
+		LocaleEN: "    size:       %s\n",
+		LocaleZH: "    大小:       %s\n",
+	},
+	"list.long.installed": {
+		LocaleEN: "    installed:  %s\n",
+		LocaleZH: "    安装时间:   %s\n",
+	},
+	"list.long.source": {
+		LocaleEN: "    source:     %s\n",
+		LocaleZH: "    下载地址:   %s\n",
+	},
+	"list.long.mirror": {
+		LocaleEN: "    mirror:     %s\n",
+		LocaleZH: "    镜像:       %s\n",
+	},
+	"list.long.imported": {
+		LocaleEN: "    imported:   %s (%s)\n",
+		LocaleZH: "    导入自:     %s (%s)\n",
+	},
+	"list.long.sha256": {
+		LocaleEN: "    sha256:     %s\n",
+		LocaleZH: "    sha256:     %s\n",
+	},
+	"list.long.last_used": {
+		LocaleEN: "    last used:  %s\n",
+		LocaleZH: "    最后使用:   %s\n",
+	},
+	"list.long.never": {
+		LocaleEN: "never",
+		LocaleZH: "从未使用",
+	},
+	"list.long.pinned_by": {
+		LocaleEN: "    pinned by:  %s\n",
+		LocaleZH: "    被固定于:   %s\n",
+	},
+	"list.long.eol": {
+		LocaleEN: "    audit:      past end of support\n",
+		LocaleZH: "    审计:       已停止支持\n",
+	},
+	"list.long.vulns": {
+		LocaleEN: "    audit:      %s\n",
+		LocaleZH: "    审计:       %s\n",
+	},
+	"flag.current.explain": {
+		LocaleEN: "show where the version comes from, the binary that runs and what shadows it on PATH",
+		LocaleZH: "显示版本的来源、实际运行的可执行文件以及PATH中覆盖它的go",
+	},
+	"flag.ls-remote.stable": {
+		LocaleEN: "list stable versions only",
+		LocaleZH: "只列出正式版本",
+	},
+	"flag.ls-remote.prerelease": {
+		LocaleEN: "list prereleases (rc|beta) only",
+		LocaleZH: "只列出预发布版本 (rc|beta)",
+	},
+	"flag.ls-remote.since": {
+		LocaleEN: "list the versions from a minor version or version on, e.g. 1.18",
+		LocaleZH: "只列出从某个次版本或版本开始的版本, 例如 1.18",
+	},
+	"flag.ls-remote.minor": {
+		LocaleEN: "list the versions of one minor version, e.g. 1.21",
+		LocaleZH: "只列出某个次版本的版本, 例如 1.21",
+	},
+	"flag.ls-remote.latest-per-minor": {
+		LocaleEN: "list the latest version of each minor version only",
+		LocaleZH: "每个次版本只列出最新的版本",
+	},
+	"flag.ls-remote.available-for": {
+		LocaleEN: "list the versions with an archive for os/arch, e.g. linux/arm64",
+		LocaleZH: "只列出有 操作系统/架构 压缩包的版本, 例如 linux/arm64",
+	},
+	"flag.ls-remote.format": {
+		LocaleEN: "output format: table, plain (one version per line) or json",
+		LocaleZH: "输出格式: table、plain (每行一个版本) 或 json",
+	},
+	"flag.ls-remote.installed": {
+		LocaleEN: "mark the installed versions with ✓",
+		LocaleZH: "用 ✓ 标出已安装的版本",
+	},
+	"flag.list.long": {
+		LocaleEN: "show the size, install receipt, last use and pinning projects of each version",
+		LocaleZH: "显示每个版本的大小、安装记录、最后使用时间和固定该版本的项目",
+	},
+	"list.aliases": {
+		LocaleEN: " (%s)",
+		LocaleZH: " (%s)",
+	},
+	"list.audit_marker": {
+		LocaleEN: " !",
+		LocaleZH: " !",
+	},
+	"list.audit_hint": {
+		LocaleEN: "! past end of support or known vulnerabilities, see govm audit\n",
+		LocaleZH: "! 已停止支持或存在已知漏洞, 详见 govm audit\n",
+	},
+	"list.system": {
+		LocaleEN: " (system)",
+		LocaleZH: " (系统)",
+	},
+
+	// command line
+	"usage.title": {
+		LocaleEN: "Go Version Manager.",
+		LocaleZH: "Go版本管理器.",
+	},
+	"usage.heading": {
+		LocaleEN: "Usage",
+		LocaleZH: "使用指南",
+	},
+	"usage.aliases": {
+		LocaleEN: "Aliases",
+		LocaleZH: "别名",
+	},
+	"usage.alias_of": {
+		LocaleEN: "alias of %s",
+		LocaleZH: "%s的别名",
+	},
+	"usage.options": {
+		LocaleEN: "Options",
+		LocaleZH: "选项",
+	},
+	"usage.global_options": {
+		LocaleEN: "Global options",
+		LocaleZH: "全局选项",
+	},
+	"usage.command_help": {
+		LocaleEN: "<command> --help",
+		LocaleZH: "<命令> --help",
+	},
+	"usage.command_help_summary": {
+		LocaleEN: "show the help of a command",
+		LocaleZH: "显示命令的帮助信息",
+	},
+	"usage.examples": {
+		LocaleEN: "Examples",
+		LocaleZH: "使用例子",
+	},
+	"usage.example.minor": {
+		LocaleEN: "use go 1.16",
+		LocaleZH: "使用1.16   版本的go",
+	},
+	"usage.example.patch": {
+		LocaleEN: "use go 1.16.1",
+		LocaleZH: "使用1.16.1 版本的go",
+	},
+	"usage.example.rc": {
+		LocaleEN: "use go 1.16rc1",
+		LocaleZH: "使用1.16rc1版本的go",
+	},
+	"usage.example.minor_latest": {
+		LocaleEN: "use the latest go 1.16",
+		LocaleZH: "使用1.16最新版本的go",
+	},
+	"usage.example.minor_dev_latest": {
+		LocaleEN: "use the latest go 1.16, including rc and beta",
+		LocaleZH: "使用1.16最新版本的go, 包括rc和beta",
+	},
+	"usage.example.latest": {
+		LocaleEN: "use the latest available go",
+		LocaleZH: "使用最新可用版本的go",
+	},
+	"usage.example.dev_latest": {
+		LocaleEN: "use the latest available go, including rc and beta",
+		LocaleZH: "使用最新可用版本的go,包括rc和beta",
+	},
+	"usage.example.tilde": {
+		LocaleEN: "use the latest go 1.21, at least 1.21.3",
+		LocaleZH: "使用1.21最新版本的go, 至少1.21.3",
+	},
+	"usage.example.range": {
+		LocaleEN: "use the latest go in a range",
+		LocaleZH: "使用范围内最新版本的go",
+	},
+	"usage.example.oldstable": {
+		LocaleEN: "use the latest go of the previous minor version",
+		LocaleZH: "使用上一个次版本的最新go",
+	},
+	"usage.install_path": {
+		LocaleEN: "Install path",
+		LocaleZH: "安装路径",
+	},
+	"usage.install_path_hint": {
+		LocaleEN: "Add the following to your ~/.bashrc or ~/.zshrc to put GoVM on your PATH",
+		LocaleZH: "将下面信息添加到你的~/.bashrc或~/.zshrc把GoVM加入环境变量",
+	},
+	"arg.version": {
+		LocaleEN: "<version>",
+		LocaleZH: "<版本>",
+	},
+	"arg.notes": {
+		LocaleEN: "<version|from..to>",
+		LocaleZH: "<版本|从..到>",
+	},
+	"arg.command": {
+		LocaleEN: "[command]",
+		LocaleZH: "[命令]",
+	},
+	"arg.import": {
+		LocaleEN: "[path]",
+		LocaleZH: "[路径]",
+	},
+	"arg.alias": {
+		LocaleEN: "<list|set|rm|update>",
+		LocaleZH: "<list|set|rm|update>",
+	},
+	"arg.dir": {
+		LocaleEN: "[dir]",
+		LocaleZH: "[目录]",
+	},
+	"arg.config": {
+		LocaleEN: "<list|get|set>",
+		LocaleZH: "<list|get|set>",
+	},
+	"arg.alias.set": {
+		LocaleEN: "<name> <version>",
+		LocaleZH: "<名称> <版本>",
+	},
+	"arg.alias.rm": {
+		LocaleEN: "<name>",
+		LocaleZH: "<名称>",
+	},
+	"arg.config.get": {
+		LocaleEN: "<key>",
+		LocaleZH: "<配置项>",
+	},
+	"arg.config.set": {
+		LocaleEN: "<key> <value>",
+		LocaleZH: "<配置项> <值>",
+	},
+	"arg.exec": {
+		LocaleEN: "<version> -- <command>",
+		LocaleZH: "<版本> -- <命令>",
+	},
+	"arg.verify": {
+		LocaleEN: "[version]",
+		LocaleZH: "[版本]",
+	},
+	"arg.store": {
+		LocaleEN: "<stats|gc>",
+		LocaleZH: "<stats|gc>",
+	},
+	"cmd.use": {
+		LocaleEN: "install and use <version>",
+		LocaleZH: "安装并设置使用 <版本>",
+	},
+	"cmd.list": {
+		LocaleEN: "installed versions (managed by GoVM only)",
+		LocaleZH: "已经安装的版本(仅限GoVM管理的版本)",
+	},
+	"cmd.ls-remote": {
+		LocaleEN: "remote versions (including rc|beta versions)",
+		LocaleZH: "远程版本列表 (包括 rc|beta 版本)",
+	},
+	"cmd.install": {
+		LocaleEN: "install <version> (from the mirror.registry config setting)",
+		LocaleZH: "安装 <版本> (从配置项mirror.registry指定的镜像下载)",
+	},
+	"cmd.uninstall": {
+		LocaleEN: "uninstall <version>",
+		LocaleZH: "卸载<版本>",
+	},
+	"cmd.current": {
+		LocaleEN: "show the version in use",
+		LocaleZH: "显示当前使用的版本",
+	},
+	"cmd.env": {
+		LocaleEN: "show the GoVM environment",
+		LocaleZH: "显示GoVM环境信息",
+	},
+	"cmd.config": {
+		LocaleEN: "show or change the settings in config.toml",
+		LocaleZH: "查看或修改config.toml中的配置",
+	},
+	"cmd.import": {
+		LocaleEN: "bring the Go installation at [path] under govm, or find them with --scan",
+		LocaleZH: "将[路径]下的Go安装交给GoVM管理, 或用--scan查找",
+	},
+	"cmd.alias": {
+		LocaleEN: "name versions, e.g. alias set legacy 1.19.13, and use the names like versions",
+		LocaleZH: "为版本命名, 例如 alias set legacy 1.19.13, 之后可以像版本一样使用名称",
+	},
+	"cmd.audit": {
+		LocaleEN: "report installed versions past end of support or with known vulnerabilities",
+		LocaleZH: "报告已停止支持或存在已知漏洞的已安装版本",
+	},
+	"cmd.upgrade-go": {
+		LocaleEN: "install the latest patch of every installed minor version and move current and aliases to it",
+		LocaleZH: "为每个已安装的次版本安装最新的补丁版本, 并把当前版本和别名切换过去",
+	},
+	"cmd.migrate-home": {
+		LocaleEN: "move GoVM to [dir], or to the XDG dirs without one",
+		LocaleZH: "将GoVM移动到[目录], 不指定目录时移动到XDG目录",
+	},
+	"cmd.notes": {
+		LocaleEN: "show the fixes of <version>, or of the releases after <from> up to <to>, from the release history",
+		LocaleZH: "从发布历史中显示<版本>的修复内容, 或<从>之后直到<到>的每个版本的修复内容",
+	},
+	"cmd.which": {
+		LocaleEN: "show the go binary of the version that applies in this directory",
+		LocaleZH: "显示当前目录适用版本的go可执行文件",
+	},
+	"cmd.doctor": {
+		LocaleEN: "check PATH, GOROOT, other Go installs and the versions directory",
+		LocaleZH: "检查PATH、GOROOT、其他Go安装和版本目录",
+	},
+	"cmd.exec": {
+		LocaleEN: "run <command> with <version> without switching to it",
+		LocaleZH: "使用<版本>运行<命令>, 不切换当前版本",
+	},
+	"cmd.prune": {
+		LocaleEN: "remove old versions by policy (shows what it would remove without --yes)",
+		LocaleZH: "按策略删除旧版本 (不加--yes时只显示将删除的版本)",
+	},
+	"flag.prune.keep-latest-patch": {
+		LocaleEN: "keep the latest patch of each minor version",
+		LocaleZH: "保留每个次版本的最新补丁版本",
+	},
+	"flag.prune.keep-recent": {
+		LocaleEN: "keep the `N` most recently used versions",
+		LocaleZH: "保留最近使用的 `N` 个版本",
+	},
+	"flag.prune.keep-pinned": {
+		LocaleEN: "keep the versions pinned by .go-version or go.mod files under `dir` (repeatable)",
+		LocaleZH: "保留 `dir` 下的项目在.go-version或go.mod中固定的版本 (可重复)",
+	},
+	"flag.prune.prerelease-older-than": {
+		LocaleEN: "remove rc and beta versions not used for `age`, e.g. 30d",
+		LocaleZH: "删除超过 `age` 没有使用的rc和beta版本, 如 30d",
+	},
+	"flag.import.scan": {
+		LocaleEN: "list the Go installations found on this machine",
+		LocaleZH: "列出本机上找到的Go安装",
+	},
+	"flag.import.mode": {
+		LocaleEN: "`mode` of import: copy, move or link (default copy)",
+		LocaleZH: "导入方式`mode`: copy 复制、move 移动或 link 链接 (默认copy)",
+	},
+	"flag.notes.file": {
+		LocaleEN: "read a saved copy of go.dev/doc/devel/release instead of fetching it",
+		LocaleZH: "读取保存的go.dev/doc/devel/release页面, 不从网络获取",
+	},
+	"flag.audit.refresh": {
+		LocaleEN: "download the Go vulnerability database again",
+		LocaleZH: "重新下载Go漏洞数据库",
+	},
+	"flag.audit.db": {
+		LocaleEN: "use a vulndb.zip or JSON file of OSV entries as the vulnerability database",
+		LocaleZH: "使用vulndb.zip或OSV条目的JSON文件作为漏洞数据库",
+	},
+	"flag.audit.offline": {
+		LocaleEN: "use the cached data only",
+		LocaleZH: "只使用缓存的数据",
+	},
+	"flag.upgrade-go.current": {
+		LocaleEN: "upgrade the minor version of the current version only",
+		LocaleZH: "只升级当前版本所在的次版本",
+	},
+	"flag.upgrade-go.prune": {
+		LocaleEN: "remove the patches the upgrade supersedes",
+		LocaleZH: "删除被升级取代的补丁版本",
+	},
+	"flag.upgrade-go.dry-run": {
+		LocaleEN: "only show the plan",
+		LocaleZH: "只显示升级计划",
+	},
+	"flag.doctor.fix": {
+		LocaleEN: "fix the problems that govm can fix by itself",
+		LocaleZH: "修复GoVM可以自动修复的问题",
+	},
+	"flag.doctor.online": {
+		LocaleEN: "also check that mirror.registry can be reached",
+		LocaleZH: "同时检查能否访问mirror.registry",
+	},
+	"flag.prune.yes": {
+		LocaleEN: "remove the versions instead of only showing them",
+		LocaleZH: "真正删除版本, 而不是只显示",
+	},
+	"cmd.verify": {
+		LocaleEN: "check the files of [version] (default: current) against the install manifest",
+		LocaleZH: "根据安装时记录的清单检查[版本]的文件 (默认当前版本)",
+	},
+	"flag.verify.all": {
+		LocaleEN: "check every installed version",
+		LocaleZH: "检查所有已安装的版本",
+	},
+	"cmd.repair": {
+		LocaleEN: "reinstall a damaged <version> from the kept archive or a mirror",
+		LocaleZH: "从保留的压缩包或镜像重新安装损坏的<版本>",
+	},
+	"cmd.store": {
+		LocaleEN: "show the space saved by the store (config store.dedupe) or clean it",
+		LocaleZH: "显示存储(配置项store.dedupe)节省的空间或清理存储",
+	},
+	"cmd.self-update": {
+		LocaleEN: "upgrade GoVM itself",
+		LocaleZH: "GoVM自身升级",
+	},
+	"cmd.version": {
+		LocaleEN: "show the GoVM version",
+		LocaleZH: "显示GoVM版本",
+	},
+	"flag.self-update.version": {
+		LocaleEN: "install this release instead of the latest one",
+		LocaleZH: "安装指定版本而不是最新版本",
+	},
+	"flag.self-update.check": {
+		LocaleEN: "only report whether an update is available",
+		LocaleZH: "只检查是否有新版本",
+	},
+	"flag.self-update.channel": {
+		LocaleEN: "release channel: stable or prerelease (default: config update.channel)",
+		LocaleZH: "发布渠道: stable 或 prerelease (默认为配置项 update.channel)",
+	},
+	"cmd.help": {
+		LocaleEN: "show this help",
+		LocaleZH: "显示此帮助信息",
+	},
+	"flag.home": {
+		LocaleEN: "GoVM install directory (default $HOME/.govm)",
+		LocaleZH: "GoVM安装目录 (默认 $HOME/.govm)",
+	},
+	"flag.registry": {
+		LocaleEN: "mirror to download Go from (default https://golang.org/dl/)",
+		LocaleZH: "下载Go的镜像地址 (默认 https://golang.org/dl/)",
+	},
+	"flag.system": {
+		LocaleEN: "install into and uninstall from the shared system store (needs admin rights)",
+		LocaleZH: "在所有用户共享的系统目录中安装和卸载 (需要管理员权限)",
+	},
+	"flag.quiet": {
+		LocaleEN: "print results and errors only",
+		LocaleZH: "只输出结果和错误信息",
+	},
+	"flag.verbose": {
+		LocaleEN: "print details such as download urls and paths",
+		LocaleZH: "输出下载地址、路径等详细信息",
+	},
+	"flag.json": {
+		LocaleEN: "print results as JSON",
+		LocaleZH: "以JSON格式输出",
+	},
+	"flag.no-color": {
+		LocaleEN: "disable colors (NO_COLOR works too)",
+		LocaleZH: "禁用彩色输出 (也可设置NO_COLOR环境变量)",
+	},
+	"flag.debug": {
+		LocaleEN: "print a debug log of HTTP requests and file operations",
+		LocaleZH: "输出HTTP请求和文件操作的调试日志",
+	},
+	"flag.log-format": {
+		LocaleEN: "debug log format: text or json",
+		LocaleZH: "调试日志格式: text 或 json",
+	},
+	"flag.log-file": {
+		LocaleEN: "write the debug log to ~/.govm/logs/",
+		LocaleZH: "将调试日志写入 ~/.govm/logs/",
+	},
+	"flag.help": {
+		LocaleEN: "show help",
+		LocaleZH: "显示帮助信息",
+	},
+	"cli.unknown_command": {
+		LocaleEN: "unknown command: %s",
+		LocaleZH: "未知命令: %s",
+	},
+	"cli.did_you_mean": {
+		LocaleEN: "did you mean: %s",
+		LocaleZH: "你是不是想要: %s",
+	},
+	"cli.see_command_help": {
+		LocaleEN: "run 'govm %s --help' for usage\n",
+		LocaleZH: "运行 'govm %s --help' 查看用法\n",
+	},
+	"cli.see_help": {
+		LocaleEN: "run 'govm help' for usage\n",
+		LocaleZH: "运行 'govm help' 查看用法\n",
+	},
+	"cli.missing_args": {
+		LocaleEN: "%s requires %s",
+		LocaleZH: "%s 缺少参数 %s",
+	},
+	"cli.too_many_args": {
+		LocaleEN: "%s got too many arguments: %s",
+		LocaleZH: "%s 参数过多: %s",
+	},
+	"cli.no_json": {
+		LocaleEN: "%s does not support --json",
+		LocaleZH: "%s 不支持 --json",
+	},
+	"cli.bad_format": {
+		LocaleEN: "unknown format %q, use table, plain or json",
+		LocaleZH: "未知的格式 %q, 可选 table、plain 或 json",
+	},
+	"cli.version_from": {
+		LocaleEN: "[Info] Using %s from %s\n",
+		LocaleZH: "[信息] 使用版本 %s, 来自 %s\n",
+	},
+	"cli.error": {
+		LocaleEN: "[Error] %s\n",
+		LocaleZH: "[错误] %s\n",
+	},
+}
@@ -0,0 +1,60 @@
+package govm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMessagesAreTranslated(t *testing.T) {
+	for key, translations := range messages {
+		for _, l := range Locales {
+			if translations[l] == "" {
+				t.Errorf("message %q has no %s translation", key, l)
+			}
+		}
+	}
+}
+
+// anyArg can be formatted with any verb
+type anyArg struct{}
+
+func (anyArg) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, "x")
+}
+
+func TestMessageArguments(t *testing.T) {
+	for key, translations := range messages {
+		n := strings.Count(strings.ReplaceAll(translations[LocaleEN], "%%", ""), "%")
+		args := make([]interface{}, n)
+		for i := range args {
+			args[i] = anyArg{}
+		}
+		for _, l := range Locales {
+			if msg := fmt.Sprintf(translations[l], args...); strings.Contains(msg, "%!") {
+				t.Errorf("message %q in %s does not take the %d arguments of the English message: %s", key, l, n, msg)
+			}
+		}
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	cases := map[string]string{
+		"zh_CN.UTF-8": LocaleZH,
+		"zh_TW":       LocaleZH,
+		"en_US.UTF-8": LocaleEN,
+		"C":           LocaleEN,
+		"de_DE":       LocaleEN,
+	}
+	for value, want := range cases {
+		if got := MatchLocale(value); got != want {
+			t.Errorf("MatchLocale(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestUnknownMessageKey(t *testing.T) {
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,275 @@
+package govm
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How Import brings a Go installation under govm
+const (
+	ImportCopy = "copy"
+	ImportMove = "move"
+	ImportLink = "link"
+)
+
+// importRoots are the globs of the GOROOTs that Go installers, package
+// managers and golang.org/dl usually create, ~ is the home directory
+var importRoots = func() []string {
+	if runtime.GOOS == "windows" {
+		return []string{`C:\Program Files\Go`, `C:\Go`, `~\sdk\go*`}
+	}
+	return []string{
+		"/usr/local/go",
+		"/usr/lib/go",
+		"/usr/lib/go-*",
+		"/usr/lib/golang",
+		"/opt/homebrew/Cellar/go/*/libexec",
+		"/usr/local/Cellar/go/*/libexec",
+		"/home/linuxbrew/.linuxbrew/Cellar/go/*/libexec",
+		"/snap/go/current",
+		"~/sdk/go*",
+	}
+}()
+
+// ImportCandidate is a Go installation outside govm
+type ImportCandidate struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Installed tells that govm already has this version
+	Installed bool `json:"installed"`
+}
+
+// Import brings the Go installation at path, its GOROOT or its go command,
+// under govm as a version with a receipt. The tree is copied, moved or
+// linked, and staged and validated like a download.
+func (g *GoVM) Import(path, mode string) (Receipt, error) {
+	if mode != ImportCopy && mode != ImportMove && mode != ImportLink {
+		return Receipt{}, errors.New(T("import.bad_mode", mode))
+	}
+	goroot, version, err := detectGoRoot(path)
+	if err != nil {
+		return Receipt{}, err
+	}
+	if g.managedPath(goroot) {
+		return Receipt{}, errors.New(T("import.managed", goroot))
+	}
+	if _, _, ok := g.findVersion(version); ok {
+		return Receipt{}, errors.New(T("import.exists", version))
+	}
+	if g.system {
+		if err := g.checkSystemStore(); err != nil {
+			return Receipt{}, err
+		}
+	}
+
+	g.mkdirs()
+	g.cleanStaleStaging()
+	unlock, err := g.lockVersion(version)
+	if err != nil {
+		return Receipt{}, err
+	}
+	defer unlock()
+	if _, _, ok := g.findVersion(version); ok {
+		return Receipt{}, errors.New(T("import.exists", version))
+	}
+	staging, err := g.newStagingDir()
+	if err != nil {
+		return Receipt{}, err
+	}
+	tree := filepath.Join(staging, "go")
+	switch mode {
+	case ImportCopy:
+		err = copyTree(goroot, tree)
+	case ImportMove:
+		err = movePath(goroot, tree)
+	case ImportLink:
+		err = os.Symlink(goroot, tree)
+		traceFS("symlink", tree, err, "target", goroot)
+	}
+	r := Receipt{Version: version, Source: goroot, Import: mode, InstalledAt: time.Now()}
+	if err == nil {
+		err = validateToolchain(version, staging)
+	}
+	if err == nil {
+		err = writeReceiptFile(staging, r)
+	}
+	if err == nil {
+		g.finishInstall(version, staging, nil)
+		dir := g.getVersionDir(version)
+		err = os.Rename(staging, dir)
+		traceFS("rename", staging, err, "target", dir)
+	}
+	if err != nil {
+		if mode == ImportMove {
+			if _, statErr := os.Lstat(tree); statErr == nil {
+				// put the tree back where the user had it
+				if moveErr := movePath(tree, goroot); moveErr != nil {
+					return Receipt{}, g.keepImportTree(staging, tree, version, goroot, err, moveErr)
+				}
+			}
+		}
+		removeErr := os.RemoveAll(staging)
+		traceFS("remove", staging, removeErr)
+		return Receipt{}, errors.New(T("import.failed", goroot, err))
+	}
+	tracer.Event("import", "version", version, "path", goroot, "mode", mode)
+	return r, nil
+}
+
+// keepImportTree saves the moved tree that cannot be put back to goroot out
+// of the staging dir, which cleanStaleStaging removes, as it may be the
+// only copy left
+func (g *GoVM) keepImportTree(staging, tree, version, goroot string, err, moveErr error) error {
+	kept := filepath.Join(g.versionsDir, importKeptPrefix+version+"-"+strconv.FormatInt(time.Now().Unix(), 10))
+	renameErr := os.Rename(tree, kept)
+	traceFS("rename", tree, renameErr, "target", kept)
+	if renameErr != nil {
+		kept = tree
+	} else {
+		removeErr := os.RemoveAll(staging)
+		traceFS("remove", staging, removeErr)
+	}
+	return errors.New(T("import.restore_failed", goroot, err, moveErr, kept))
+}
+
+// ScanImports finds the Go installations in the usual places and on PATH,
+// leaving out the versions of govm
+func (g *GoVM) ScanImports() []ImportCandidate {
+	home, _ := os.UserHomeDir()
+	var paths []string
+	for _, pattern := range importRoots {
+		if strings.HasPrefix(pattern, "~") {
+			if home == "" {
+				continue
+			}
+			pattern = filepath.Join(home, pattern[1:])
+		}
+		matches, _ := filepath.Glob(pattern)
+		paths = append(paths, matches...)
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			paths = append(paths, filepath.Join(dir, goBinary()))
+		}
+	}
+
+	var candidates []ImportCandidate
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		goroot, version, err := detectGoRoot(path)
+		if err != nil || seen[goroot] || g.managedPath(goroot) {
+			continue
+		}
+		seen[goroot] = true
+		_, _, installed := g.findVersion(version)
+		candidates = append(candidates, ImportCandidate{Path: goroot, Version: version, Installed: installed})
+	}
+	return candidates
+}
+
+// managedPath tells whether path is inside govm's own directories
+func (g *GoVM) managedPath(path string) bool {
+	for _, dir := range []string{g.installDir, g.systemVersionsDir} {
+		if dir == "" {
+			continue
+		}
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = real
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectGoRoot resolves path, a GOROOT or a go command, to the real GOROOT
+// and reads its version from the VERSION file or else from go version
+func detectGoRoot(path string) (string, string, error) {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", "", err
+	}
+	if abs, err := filepath.Abs(real); err == nil {
+		real = abs
+	}
+	goroot := real
+	if info, err := os.Stat(real); err == nil && !info.IsDir() {
+		goroot = filepath.Dir(filepath.Dir(real))
+	}
+	bin := filepath.Join(goroot, "bin", goBinary())
+	if info, err := os.Stat(bin); err != nil || info.IsDir() {
+		return "", "", errors.New(T("import.not_goroot", path))
+	}
+
+	name := readVersionFile(filepath.Join(goroot, "VERSION"))
+	if name == "" {
+		// #nosec G204
+		cmd := exec.Command(bin, "version")
+		cmd.Dir = goroot
+		cmd.Env = append(os.Environ(), "GOROOT="+goroot, "GOTOOLCHAIN=local")
+		out, err := cmd.Output()
+		tracer.Event("exec", "path", bin, "args", "version", "error", err)
+		// go version go1.21.3 linux/amd64
+		if fields := strings.Fields(string(out)); err == nil && len(fields) >= 3 {
+			name = fields[2]
+		}
+	}
+	v, err := ParseVersion(strings.TrimPrefix(name, "go"))
+	if err != nil {
+		return "", "", errors.New(T("import.unknown_version", goroot, name))
+	}
+	return goroot, v.String(), nil
+}
+
+// readVersionFile reads the first line of the VERSION file of a GOROOT
+func readVersionFile(path string) string {
+	//#nosec G304
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+	scanner := bufio.NewScanner(f)
+	if scanner.Scan() {
+		return strings.TrimSpace(scanner.Text())
+	}
+	return ""
+}
+
+// linkedTree tells whether the go tree of a version dir links to an
+// installation imported with ImportLink, which govm must never change
+func linkedTree(dir string) bool {
+	info, err := os.Lstat(filepath.Join(dir, "go"))
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// walkGoTree walks dir/go like filepath.WalkDir, following the link of a
+// linked import but still reporting the paths below dir/go
+func walkGoTree(dir string, fn fs.WalkDirFunc) error {
+	root := filepath.Join(dir, "go")
+	if !linkedTree(dir) {
+		return filepath.WalkDir(root, fn)
+	}
+	real, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	return filepath.WalkDir(real, func(path string, d fs.DirEntry, err error) error {
+		rel, relErr := filepath.Rel(real, path)
+		if relErr != nil {
+			return relErr
+		}
+		return fn(filepath.Join(root, rel), d, err)
+	})
+}
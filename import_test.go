@@ -0,0 +1,190 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeGoRoot creates a GOROOT whose go command reports version, with a
+// VERSION file when versionFile is set
+func fakeGoRoot(t *testing.T, version string, versionFile bool) string {
+	t.Helper()
+	goroot := filepath.Join(t.TempDir(), "go")
+	bin := filepath.Join(goroot, "bin")
+	if err := os.MkdirAll(bin, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "go"), []byte("#!/bin/sh\necho go version go"+version+" linux/amd64\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if versionFile {
+		if err := os.WriteFile(filepath.Join(goroot, "VERSION"), []byte("go"+version+"\ntime 2023-10-09T17:04:35Z\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return goroot
+}
+
+func TestDetectGoRoot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go command is a shell script")
+	}
+	fromFile := fakeGoRoot(t, "1.21.3", true)
+	fromCommand := fakeGoRoot(t, "1.16", false)
+	for path, want := range map[string]string{
+		fromFile:                                "1.21.3",
+		filepath.Join(fromFile, "bin", "go"):    "1.21.3",
+		fromCommand:                             "1.16",
+		filepath.Join(fromCommand, "bin", "go"): "1.16",
+	} {
+		goroot, version, err := detectGoRoot(path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if version != want || filepath.Base(goroot) != "go" {
+			t.Errorf("%s: %s %s, want version %s", path, goroot, version, want)
+		}
+	}
+	if _, _, err := detectGoRoot(t.TempDir()); err == nil {
+		t.Error("accepted a directory without go command")
+	}
+}
+
+func TestImport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go command is a shell script")
+	}
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copied := fakeGoRoot(t, "1.20.1", true)
+	moved := fakeGoRoot(t, "1.20.2", true)
+	linked := fakeGoRoot(t, "1.20.3", true)
+	for path, mode := range map[string]string{copied: ImportCopy, moved: ImportMove, linked: ImportLink} {
+		r, err := g.Import(path, mode)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if r.Source != path || r.Import != mode {
+			t.Errorf("%s: receipt %+v", mode, r)
+		}
+		if r, err := g.readReceipt(r.Version); err != nil || r.Import != mode {
+			t.Errorf("%s: written receipt %+v %v", mode, r, err)
+		}
+		if res, err := g.Verify(r.Version); err != nil || !res.OK() || res.NoManifest {
+			t.Errorf("%s: verify %+v %v", mode, res, err)
+		}
+	}
+	if _, err := os.Stat(copied); err != nil {
+		t.Errorf("copy removed the source: %v", err)
+	}
+	if _, err := os.Stat(moved); !os.IsNotExist(err) {
+		t.Errorf("move kept the source: %v", err)
+	}
+	if !linkedTree(g.getVersionDir("1.20.3")) {
+		t.Error("link import is no link")
+	}
+	if _, err := g.Import(copied, ImportCopy); err == nil {
+		t.Error("imported an installed version again")
+	}
+	if _, err := g.Import(filepath.Join(g.getVersionDir("1.20.1"), "go"), ImportLink); err == nil {
+		t.Error("imported a version of govm")
+	}
+	if err := g.Repair("1.20.3"); err == nil {
+		t.Error("repaired a linked import")
+	}
+
+	g.changeSymblinkGoBin("1.20.3")
+	if v := g.CurrentVersion(); v != "1.20.3" {
+		t.Errorf("current version of a linked import is %q", v)
+	}
+	g.changeSymblinkGoBin("1.20.1")
+	g.Uninstall("1.20.3")
+	if _, err := os.Stat(filepath.Join(linked, "bin", "go")); err != nil {
+		t.Errorf("uninstall removed the linked installation: %v", err)
+	}
+}
+
+func TestImportMoveRollback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go command is a shell script")
+	}
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the VERSION file does not match the go command, so validation fails
+	goroot := fakeGoRoot(t, "1.20.4", false)
+	if err := os.WriteFile(filepath.Join(goroot, "VERSION"), []byte("go1.20.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// across file systems the tree is copied there and back
+	crossDevice(t, nil)
+	if _, err := g.Import(goroot, ImportMove); err == nil {
+		t.Fatal("imported a broken installation")
+	}
+	if _, err := os.Stat(filepath.Join(goroot, "bin", "go")); err != nil {
+		t.Errorf("tree not moved back: %v", err)
+	}
+
+	// something took the place of the tree, moving it back fails
+	crossDevice(t, func(from, to string) {
+		if to == goroot {
+			_ = os.WriteFile(goroot, []byte("taken"), 0644)
+		}
+	})
+	_, err = g.Import(goroot, ImportMove)
+	if err == nil {
+		t.Fatal("imported a broken installation")
+	}
+	kept, _ := filepath.Glob(filepath.Join(g.versionsDir, importKeptPrefix+"1.20.5-*"))
+	if len(kept) != 1 {
+		t.Fatalf("kept trees %v: %v", kept, err)
+	}
+	if _, err := os.Stat(filepath.Join(kept[0], "bin", "go")); err != nil {
+		t.Errorf("moved tree lost: %v", err)
+	}
+	g.cleanStaleStaging()
+	if _, err := os.Stat(kept[0]); err != nil {
+		t.Errorf("kept tree cleaned up: %v", err)
+	}
+	if versions, _ := g.InstalledVersions(false); len(versions) != 0 {
+		t.Errorf("versions %+v", versions)
+	}
+}
+
+func TestScanImports(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go command is a shell script")
+	}
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(g.getVersionDir("1.21.3"), "go"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	installed := fakeGoRoot(t, "1.21.3", true)
+	onPath := fakeGoRoot(t, "1.19.2", false)
+	defer func(roots []string) { importRoots = roots }(importRoots)
+	importRoots = []string{installed, filepath.Join(t.TempDir(), "missing")}
+	t.Setenv("PATH", filepath.Join(onPath, "bin")+string(os.PathListSeparator)+filepath.Join(installed, "bin"))
+
+	got := g.ScanImports()
+	want := []ImportCandidate{{Path: installed, Version: "1.21.3", Installed: true}, {Path: onPath, Version: "1.19.2"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ScanImports = %+v, want %+v", got, want)
+	}
+}
@@ -1,6 +1,23 @@
 #!/bin/sh
 
 GOVM_BIN_DIR=$HOME/.govm/bin
+
+# messages follow GOVM_LANG, LC_ALL, LC_MESSAGES and LANG like govm itself
+GOVM_LOCALE=${GOVM_LANG:-${LC_ALL:-${LC_MESSAGES:-$LANG}}}
+case $GOVM_LOCALE in
+   zh*)
+      MSG_LATEST="使用最新版本的GoVM"
+      MSG_VERSION="使用%s版本的GoVM"
+      MSG_INSTALLED="成功安装到: %s"
+      MSG_PATH="***请将以下内容手动添加到你的环境变量(~/.bashrc或~/.zshrc之类)***"
+      ;;
+   *)
+      MSG_LATEST="Installing the latest GoVM"
+      MSG_VERSION="Installing GoVM %s"
+      MSG_INSTALLED="Installed to: %s"
+      MSG_PATH="***Please add the following to your shell profile (~/.bashrc, ~/.zshrc or similar)***"
+      ;;
+esac
 mkdir -p $GOVM_BIN_DIR
 
 GOVM_ARCH_BIN=''
@@ -40,23 +57,23 @@ esac
 if [ -z "$GOVM_VERSION" ]
 then
       GOVM_VERSION=master
-      echo "使用最新版本的Govm\n"
+      printf "$MSG_LATEST\n\n"
 else
-      echo "使用$GOVM_VERSION版本的GoVM\n"
+      printf "$MSG_VERSION\n\n" "$GOVM_VERSION"
 fi
 
 curl -kLs https://github.com/TaceyWong/govm/releases/latest/download/$GOVM_ARCH_BIN -o $GOVM_BIN_DIR/govm
 
 chmod +x $GOVM_BIN_DIR/govm
 
-echo "成功安装到: $GOVM_BIN_DIR/govm"
+printf "$MSG_INSTALLED\n" "$GOVM_BIN_DIR/govm"
 
 echo "============================"
 $GOVM_BIN_DIR/govm help
 echo "============================"
 
 echo
-echo "***请将以下内容手动添加到你的环境变量(~/.bashrc或~/.zshrc之类)***"
+echo "$MSG_PATH"
 echo
 echo 'export PATH="$HOME/.govm/current/bin:$HOME/.govm/bin:$PATH"'
 echo
\ No newline at end of file
@@ -0,0 +1,51 @@
+package govm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const lastUsedFile = "last-used.json"
+
+// lastUsed maps every version to when it was last switched to or run with
+// exec. It is kept per user, also for the versions of the system store.
+func (g *GoVM) lastUsed() map[string]time.Time {
+	used := make(map[string]time.Time)
+	//#nosec G304
+	data, err := os.ReadFile(filepath.Join(g.installDir, lastUsedFile))
+	if err == nil {
+		_ = json.Unmarshal(data, &used)
+	}
+	return used
+}
+
+// touchVersion records version as used now, failures only lose the timestamp
+func (g *GoVM) touchVersion(version string) {
+	g.updateLastUsed(func(used map[string]time.Time) {
+		used[version] = time.Now().UTC()
+	})
+}
+
+// forgetVersion drops the timestamp of an uninstalled version
+func (g *GoVM) forgetVersion(version string) {
+	g.updateLastUsed(func(used map[string]time.Time) {
+		delete(used, version)
+	})
+}
+
+func (g *GoVM) updateLastUsed(fn func(map[string]time.Time)) {
+	used := g.lastUsed()
+	fn(used)
+	data, err := json.MarshalIndent(used, "", "  ")
+	if err != nil {
+		return
+	}
+	path := filepath.Join(g.installDir, lastUsedFile)
+	if err := os.MkdirAll(g.installDir, os.ModePerm); err != nil {
+		return
+	}
+	err = writeFileAtomic(path, data, 0644)
+	traceFS("write", path, err)
+}
@@ -0,0 +1,137 @@
+package govm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Layout is where govm keeps its files. A home layout keeps everything in one
+// directory, an XDG layout separates data, config, cache and logs.
+type Layout struct {
+	// Home holds the versions, the current links and the govm binary
+	Home      string `json:"home"`
+	Config    string `json:"config_dir"`
+	Downloads string `json:"downloads_dir"`
+	Cache     string `json:"cache_dir"`
+	Logs      string `json:"logs_dir"`
+	XDG       bool   `json:"xdg"`
+}
+
+// HomeLayout keeps every file of govm in dir
+func HomeLayout(dir string) Layout {
+	return Layout{
+		Home:      dir,
+		Config:    dir,
+		Downloads: filepath.Join(dir, "downloads"),
+		Cache:     filepath.Join(dir, "cache"),
+		Logs:      filepath.Join(dir, "logs"),
+	}
+}
+
+// XDGLayout follows XDG_DATA_HOME, XDG_CONFIG_HOME, XDG_CACHE_HOME and
+// XDG_STATE_HOME, or the platform defaults for the ones that are unset
+func XDGLayout() (Layout, error) {
+	userHome, homeErr := os.UserHomeDir()
+	dir := func(env string, fallback func() (string, error), rel ...string) (string, error) {
+		if p := os.Getenv(env); filepath.IsAbs(p) {
+			return filepath.Join(p, "govm"), nil
+		}
+		if fallback != nil {
+			if p, err := fallback(); err == nil {
+				return filepath.Join(p, "govm"), nil
+			}
+		}
+		if homeErr != nil {
+			return "", errors.New(T("layout.no_home"))
+		}
+		return filepath.Join(append([]string{userHome}, append(rel, "govm")...)...), nil
+	}
+
+	data, err := dir("XDG_DATA_HOME", nil, ".local", "share")
+	if err != nil {
+		return Layout{}, err
+	}
+	config, err := dir("XDG_CONFIG_HOME", os.UserConfigDir, ".config")
+	if err != nil {
+		return Layout{}, err
+	}
+	cache, err := dir("XDG_CACHE_HOME", os.UserCacheDir, ".cache")
+	if err != nil {
+		return Layout{}, err
+	}
+	state, err := dir("XDG_STATE_HOME", nil, ".local", "state")
+	if err != nil {
+		return Layout{}, err
+	}
+	return Layout{
+		Home:      data,
+		Config:    config,
+		Downloads: filepath.Join(cache, "downloads"),
+		Cache:     cache,
+		Logs:      filepath.Join(state, "logs"),
+		XDG:       true,
+	}, nil
+}
+
+// ResolveLayout picks the layout, in order of precedence, from home, GOVM_HOME,
+// an existing ~/.govm, an existing XDG data dir, the XDG layout when any XDG
+// variable is set, and else a new ~/.govm
+func ResolveLayout(home string) (Layout, error) {
+	if home == "" {
+		home = os.Getenv("GOVM_HOME")
+	}
+	if home != "" {
+		abs, err := filepath.Abs(home)
+		if err != nil {
+			return Layout{}, err
+		}
+		return HomeLayout(abs), nil
+	}
+
+	userHome, err := os.UserHomeDir()
+	if err == nil {
+		legacy := filepath.Join(userHome, goVMDir)
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			return HomeLayout(legacy), nil
+		}
+	}
+	if xdg, err := XDGLayout(); err == nil {
+		if info, err := os.Stat(xdg.Home); err == nil && info.IsDir() {
+			return xdg, nil
+		}
+	}
+	for _, name := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		if os.Getenv(name) != "" {
+			return XDGLayout()
+		}
+	}
+	if err != nil {
+		return Layout{}, errors.New(T("layout.no_home"))
+	}
+	return HomeLayout(filepath.Join(userHome, goVMDir)), nil
+}
+
+// versionsDir is the user store of l, --system installs go to the system store
+func (l Layout) versionsDir() string {
+	return filepath.Join(l.Home, "versions")
+}
+
+// setLayout derives every directory of g from l
+func (g *GoVM) setLayout(l Layout) {
+	g.layout = l
+	g.installDir = l.Home
+	g.versionsDir = l.versionsDir()
+	g.currentDir = filepath.Join(l.Home, "current")
+	g.currentBinDir = filepath.Join(l.Home, "current", "bin")
+	g.currentGoDir = filepath.Join(l.Home, "current", "go")
+	g.binDir = filepath.Join(l.Home, "bin")
+	g.downloadsDir = l.Downloads
+	g.cacheDir = l.Cache
+	g.logsDir = l.Logs
+}
+
+// Layout in use
+func (g *GoVM) Layout() Layout {
+	return g.layout
+}
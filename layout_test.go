@@ -0,0 +1,233 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestResolveLayout(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GOVM_HOME", "")
+	for _, name := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(name, "")
+	}
+
+	check := func(name string, got Layout, want string, xdg bool) {
+		t.Helper()
+		if got.Home != want || got.XDG != xdg {
+			t.Errorf("%s: got %+v, want home %s", name, got, want)
+		}
+	}
+
+	l, err := ResolveLayout("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("default", l, filepath.Join(home, ".govm"), false)
+
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	l, _ = ResolveLayout("")
+	check("xdg", l, filepath.Join(home, "data", "govm"), true)
+	if l.Cache != filepath.Join(home, ".cache", "govm") || l.Logs != filepath.Join(home, ".local", "state", "govm", "logs") {
+		t.Errorf("xdg defaults: %+v", l)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".local", "share", "govm"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_DATA_HOME", "")
+	l, _ = ResolveLayout("")
+	check("existing xdg", l, filepath.Join(home, ".local", "share", "govm"), true)
+
+	if err := os.MkdirAll(filepath.Join(home, ".govm"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	l, _ = ResolveLayout("")
+	check("existing ~/.govm", l, filepath.Join(home, ".govm"), false)
+
+	t.Setenv("GOVM_HOME", filepath.Join(home, "env"))
+	l, _ = ResolveLayout("")
+	check("GOVM_HOME", l, filepath.Join(home, "env"), false)
+
+	l, _ = ResolveLayout(filepath.Join(home, "flag"))
+	check("--home", l, filepath.Join(home, "flag"), false)
+}
+
+func TestResolveLayoutWithoutHome(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("GOVM_HOME", "")
+	for _, name := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(name, "")
+	}
+	if l, err := ResolveLayout(""); err == nil {
+		t.Fatalf("got %+v", l)
+	}
+}
+
+func TestMigrateHome(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	src := t.TempDir()
+	g, err := NewGoVmWithOptions(Options{Home: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		filepath.Join("versions", "1.17.6", "go", "bin", "go"),
+		filepath.Join("bin", "govm"),
+		filepath.Join("cache", "update-check.json"),
+		configFile,
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, f)), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, f), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.17.6")
+	g.changeSymblinkGo("1.17.6")
+	g.touchVersion("1.17.6")
+	if _, err := g.SetAlias("work", "1.17.6"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := HomeLayout(filepath.Join(t.TempDir(), "govm"))
+	if err := g.MigrateHome(dst); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.lastUsed()["1.17.6"]; !ok {
+		t.Error("last use not moved")
+	}
+	if v, ok := g.aliasVersion("work"); !ok || v != "1.17.6" {
+		t.Errorf("alias not moved: %q", v)
+	}
+	// the version is still installed in the new home
+	g.forgetRemovedVersion("1.17.6")
+	if _, ok := g.lastUsed()["1.17.6"]; !ok {
+		t.Error("last use of an installed version forgotten")
+	}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dst.Home, f)); err != nil {
+			t.Errorf("%s not moved: %v", f, err)
+		}
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source left behind: %v", err)
+	}
+	if g.CurrentVersion() != "1.17.6" {
+		t.Errorf("current link not rewritten: %q", g.CurrentVersion())
+	}
+	target, err := filepath.EvalSymlinks(filepath.Join(dst.Home, "current", "go"))
+	if err != nil || target != filepath.Join(dst.Home, "versions", "1.17.6", "go") {
+		t.Errorf("current/go points to %s, %v", target, err)
+	}
+
+	if err := g.MigrateHome(dst); err == nil {
+		t.Error("migrating to the same home succeeded")
+	}
+}
+
+// crossDevice makes renamePath fail like a rename across file systems, before
+// failing it calls also when set
+func crossDevice(t *testing.T, also func(from, to string)) {
+	t.Helper()
+	old := renamePath
+	t.Cleanup(func() { renamePath = old })
+	renamePath = func(from, to string) error {
+		if also != nil {
+			also(from, to)
+		}
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EXDEV}
+	}
+}
+
+func TestMigrateHomeCrossDevice(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	src := t.TempDir()
+	for _, version := range []string{"1.17.6", "1.17.7"} {
+		path := filepath.Join(src, "versions", version, "go", "src", "fmt", "print.go")
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package fmt"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.config.Store.Dedupe = true
+	for _, version := range []string{"1.17.6", "1.17.7"} {
+		g.finishInstall(version, g.getVersionDir(version), nil)
+	}
+
+	crossDevice(t, nil)
+	dst := HomeLayout(filepath.Join(t.TempDir(), "govm"))
+	if err := g.MigrateHome(dst); err != nil {
+		t.Fatal(err)
+	}
+	var files []os.FileInfo
+	for _, version := range []string{"1.17.6", "1.17.7"} {
+		info, err := os.Stat(filepath.Join(dst.Home, "versions", version, "go", "src", "fmt", "print.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, info)
+	}
+	m, err := readManifest(g.getVersionDir("1.17.6"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := os.Stat(filepath.Join(blobsDir(g.versionsDir), blobName(m.Files[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(files[0], files[1]) || !os.SameFile(files[0], blob) {
+		t.Error("the copy lost the links to the store")
+	}
+	if r, err := g.Verify("1.17.7"); err != nil || !r.OK() {
+		t.Errorf("verify after the copy: %+v, %v", r, err)
+	}
+}
+
+func TestMovePathCrossDevice(t *testing.T) {
+	crossDevice(t, nil)
+	from, to := filepath.Join(t.TempDir(), "go"), filepath.Join(t.TempDir(), "go")
+	for _, dir := range []string{filepath.Join(from, "src"), to} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a file where the copy needs a directory
+	if err := os.WriteFile(filepath.Join(to, "src"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := movePath(from, to); err == nil {
+		t.Fatal("copy over a file succeeded")
+	}
+	if data, err := os.ReadFile(filepath.Join(to, "src")); err != nil || string(data) != "mine" {
+		t.Errorf("existing destination changed: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(from, "src")); err != nil {
+		t.Errorf("source removed: %v", err)
+	}
+
+	created := filepath.Join(t.TempDir(), "new", "go")
+	if err := movePath(from, created); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(created, "src")); err != nil {
+		t.Errorf("not copied: %v", err)
+	}
+	if _, err := os.Stat(from); !os.IsNotExist(err) {
+		t.Errorf("source kept after the copy: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package govm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockPrefix names the lock an install, import or repair holds on a version
+// in the versions directory, the lock file holds the pid of its owner
+const lockPrefix = ".lock-"
+
+// lockVersion takes the lock on version, a lock left by a govm that is gone
+// is taken over. The returned function releases it.
+func (g *GoVM) lockVersion(version string) (func(), error) {
+	path := filepath.Join(g.versionsDir, lockPrefix+version)
+	start := time.Now()
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			traceFS("lock", path, err)
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, err
+			}
+			return func() {
+				err := os.Remove(path)
+				traceFS("remove", path, err)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		pid, stale, err := staleLock(path)
+		if os.IsNotExist(err) {
+			// released between the two calls, try again
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !stale && pid == 0 && time.Since(start) < time.Second {
+			// the owner has not written its pid yet, look again
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		if !stale {
+			return nil, errors.New(T("install.locked", version, pid))
+		}
+		DebugT("install.stale_lock", path, pid)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
+
+// versionLocked tells whether a running govm holds the lock on version
+func (g *GoVM) versionLocked(version string) bool {
+	_, stale, err := staleLock(filepath.Join(g.versionsDir, lockPrefix+version))
+	return err == nil && !stale
+}
+
+// staleLock reads the owner of the lock at path and tells whether it is
+// gone. A lock without a pid is being written unless it is old. The error
+// tells a lock that was released meanwhile apart from a held one.
+func staleLock(path string) (int, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false, err
+	}
+	if time.Since(info.ModTime()) > staleStagingAge {
+		return 0, true, nil
+	}
+	//#nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, time.Since(info.ModTime()) > time.Minute, nil
+	}
+	return pid, pid != os.Getpid() && !processAlive(pid), nil
+}
@@ -0,0 +1,85 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestLockVersion(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.mkdirs()
+	unlock, err := g.lockVersion("1.21.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(g.versionsDir, lockPrefix+"1.21.6")
+	if data, err := os.ReadFile(path); err != nil || string(data) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("lock = %q, %v", data, err)
+	}
+	if _, err := g.lockVersion("1.21.6"); err == nil {
+		t.Error("locked a version twice")
+	}
+	if versions, _ := g.InstalledVersions(false); len(versions) != 0 {
+		t.Errorf("lock listed as a version: %+v", versions)
+	}
+	unlock()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock left after unlock: %v", err)
+	}
+
+	// the lock of a govm that is gone is taken over
+	if err := os.WriteFile(path, []byte("999999999"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if pid, stale, err := staleLock(path); pid != 999999999 || !stale || err != nil {
+		t.Errorf("staleLock = %d, %v, %v", pid, stale, err)
+	}
+	unlock, err = g.lockVersion("1.21.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
+
+func TestLockVersionReleased(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.mkdirs()
+	path := filepath.Join(g.versionsDir, lockPrefix+"1.21.6")
+	if _, _, err := staleLock(path); !os.IsNotExist(err) {
+		t.Errorf("staleLock of a released lock = %v", err)
+	}
+
+	// locks released while others look at them are retried, not reported
+	// as held by pid 0
+	held := T("install.locked", "1.21.6", os.Getpid())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				unlock, err := g.lockVersion("1.21.6")
+				if err != nil {
+					if err.Error() != held {
+						t.Error(err)
+						return
+					}
+					continue
+				}
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package govm
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const manifestFile = "manifest.json"
+
+// Manifest lists every regular file of an installed go tree
+type Manifest struct {
+	Version string         `json:"version"`
+	Files   []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file of the go tree, Path is relative to the version dir
+// and slash separated
+type ManifestFile struct {
+	Path   string      `json:"path"`
+	Size   int64       `json:"size"`
+	Mode   fs.FileMode `json:"mode"`
+	SHA256 string      `json:"sha256"`
+}
+
+// buildManifest hashes every regular file below dir/go on up to Parallelism goroutines
+func (g *GoVM) buildManifest(version, dir string) (Manifest, error) {
+	m := Manifest{Version: version}
+	err := walkGoTree(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, ManifestFile{Path: filepath.ToSlash(rel), Size: info.Size(), Mode: info.Mode().Perm()})
+		return nil
+	})
+	if err != nil {
+		return m, err
+	}
+
+	errs := make([]error, len(m.Files))
+	g.forEachParallel(len(m.Files), func(i int) {
+		m.Files[i].SHA256, errs[i] = fileSHA256(filepath.Join(dir, filepath.FromSlash(m.Files[i].Path)))
+	})
+	for _, err := range errs {
+		if err != nil {
+			return m, err
+		}
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	return m, nil
+}
+
+func readManifest(versionDir string) (Manifest, error) {
+	var m Manifest
+	//#nosec G304
+	data, err := os.ReadFile(filepath.Join(versionDir, manifestFile))
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+func writeManifest(versionDir string, m Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(versionDir, manifestFile)
+	err = os.WriteFile(path, data, 0644)
+	traceFS("write", path, err)
+	return err
+}
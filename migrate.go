@@ -0,0 +1,200 @@
+package govm
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// MigrateHome moves the versions, binaries, last uses, aliases, caches, logs and config
+// of g to dst and points the current links of the active version into dst
+func (g *GoVM) MigrateHome(dst Layout) error {
+	src := g.layout
+	if g.system {
+		return errors.New(T("migrate.system"))
+	}
+	if src == dst {
+		return errors.New(T("migrate.same", dst.Home))
+	}
+	active := g.CurrentVersion()
+
+	// the config moves only when it is in the config dir, not at GOVM_CONFIG
+	srcConfig := filepath.Join(src.Config, configFile)
+	dstConfig := filepath.Join(dst.Config, configFile)
+	moves := [][2]string{
+		{g.versionsDir, dst.versionsDir()},
+		{g.binDir, filepath.Join(dst.Home, "bin")},
+		{filepath.Join(src.Home, lastUsedFile), filepath.Join(dst.Home, lastUsedFile)},
+		{filepath.Join(src.Home, aliasesFile), filepath.Join(dst.Home, aliasesFile)},
+		{filepath.Join(src.Home, "store"), filepath.Join(dst.Home, "store")},
+		{g.downloadsDir, dst.Downloads},
+		{g.cacheDir, dst.Cache},
+		{g.logsDir, dst.Logs},
+		{srcConfig, dstConfig},
+	}
+	// a directory nested in another source, e.g. the downloads in the XDG
+	// cache dir, is moved on its own and skipped by its parent
+	skip := make(map[string]bool, len(moves))
+	for _, m := range moves {
+		skip[m[0]] = true
+	}
+
+	// refuse to overwrite anything before the first file is moved
+	var entries [][2]string
+	for _, m := range moves {
+		info, err := os.Stat(m[0])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			entries = append(entries, m)
+			continue
+		}
+		children, err := os.ReadDir(m[0])
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			from := filepath.Join(m[0], child.Name())
+			if !skip[from] {
+				entries = append(entries, [2]string{from, filepath.Join(m[1], child.Name())})
+			}
+		}
+	}
+	for _, e := range entries {
+		if _, err := os.Lstat(e[1]); err == nil {
+			return errors.New(T("migrate.exists", e[1]))
+		}
+	}
+
+	copied := false
+	for _, e := range entries {
+		DebugT("migrate.moving", e[0], e[1])
+		c, err := moveOrCopy(e[0], e[1])
+		if err != nil {
+			return errors.New(T("migrate.failed", e[0], err))
+		}
+		copied = copied || c
+	}
+
+	_ = os.RemoveAll(g.currentDir)
+	moved := *g
+	moved.setLayout(dst)
+	if active != "" {
+		if err := os.MkdirAll(moved.currentDir, os.ModePerm); err != nil {
+			return err
+		}
+		moved.changeSymblinkGoBin(active)
+		moved.changeSymblinkGo(active)
+	}
+	if copied {
+		// the copies of the versions and the store share no links anymore
+		moved.relinkVersions()
+	}
+
+	// remove the emptied source directories, deepest first
+	dirs := []string{g.versionsDir, filepath.Join(src.Home, "store"), g.binDir, g.downloadsDir, g.cacheDir, g.logsDir, src.Config, src.Home}
+	if src.XDG {
+		dirs = append(dirs, filepath.Dir(g.logsDir))
+	}
+	for _, dir := range dirs {
+		if dir != "" && os.Remove(dir) == nil {
+			traceFS("remove", dir, nil)
+		}
+	}
+	if _, err := os.Stat(src.Home); err == nil {
+		InfoT("migrate.leftover", src.Home)
+	}
+	*g = moved
+	return nil
+}
+
+// renamePath is os.Rename, tests replace it to move across file systems
+var renamePath = os.Rename
+
+// movePath renames from to to, copying across file systems. A failed copy
+// removes to only when the move created it, from is left as it is.
+func movePath(from, to string) error {
+	_, err := moveOrCopy(from, to)
+	return err
+}
+
+// moveOrCopy is movePath telling whether from was copied
+func moveOrCopy(from, to string) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(to), os.ModePerm); err != nil {
+		return false, err
+	}
+	_, statErr := os.Lstat(to)
+	created := os.IsNotExist(statErr)
+	err := renamePath(from, to)
+	traceFS("rename", from, err, "target", to)
+	if err == nil {
+		return false, nil
+	}
+	if err := copyTree(from, to); err != nil {
+		if created {
+			removeErr := os.RemoveAll(to)
+			traceFS("remove", to, removeErr)
+		}
+		return true, err
+	}
+	err = os.RemoveAll(from)
+	traceFS("remove", from, err)
+	return true, err
+}
+
+// copyTree copies files, directories and symbolic links keeping their modes
+func copyTree(from, to string) error {
+	return filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func copyFile(from, to string, mode os.FileMode) error {
+	//#nosec G304
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer func(in *os.File) {
+		_ = in.Close()
+	}(in)
+	//#nosec G304
+	out, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
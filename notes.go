@@ -0,0 +1,215 @@
+package govm
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// releaseHistoryURL lists every Go release with what it fixes
+var releaseHistoryURL = "https://go.dev/doc/devel/release"
+
+const releaseHistoryCache = "release-history.html"
+
+var (
+	// releaseBlock matches the paragraphs and headings of the release history
+	releaseBlock = regexp.MustCompile(`(?is)<(p|h[1-6])\b[^>]*>(.*?)</(?:p|h[1-6])>`)
+	// releaseLine matches the start of a release, e.g. go1.21.6 (released 2024-01-09)
+	releaseLine = regexp.MustCompile(`^go(\d+(?:\.\d+){0,2}(?:(?:alpha|beta|rc)\d+)?) \(released (\d{4}-\d{2}-\d{2})\)\s*(.*)$`)
+	htmlLink    = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"`)
+	htmlCode    = regexp.MustCompile(`(?is)<code>(.*?)</code>`)
+	htmlTag     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// NotesOptions selects where the release history comes from
+type NotesOptions struct {
+	// File is a saved copy of the release history page used instead of fetching it
+	File string
+}
+
+// ReleaseNote is the entry of one release in the release history
+type ReleaseNote struct {
+	Version  string `json:"version"`
+	Released string `json:"released"`
+	// Security tells that the release includes security fixes
+	Security bool `json:"security"`
+	// Packages are the packages named by the entry
+	Packages []string `json:"packages,omitempty"`
+	Text     string   `json:"text"`
+	// Issues links to the issues fixed by the release
+	Issues string `json:"issues,omitempty"`
+}
+
+// ReleaseNotes returns the release history of version, or of every release
+// after from up to and including to for a range from..to, oldest first.
+// The range is walked in Go release order over the versions published
+// upstream, see RemoteVersions.
+func (g *GoVM) ReleaseNotes(spec string, opts NotesOptions) ([]ReleaseNote, error) {
+	data, err := g.releaseHistory(opts)
+	if err != nil {
+		return nil, err
+	}
+	notes := parseReleaseHistory(data)
+	if len(notes) == 0 {
+		return nil, errors.New(T("notes.empty"))
+	}
+	byVersion := make(map[string]ReleaseNote, len(notes))
+	names := make([]string, 0, len(notes))
+	seen := make(map[string]bool, len(notes))
+	for _, n := range notes {
+		byVersion[n.Version] = n
+		names = append(names, n.Version)
+		seen[n.Version] = true
+	}
+	// a saved page is used offline, its releases are the index then
+	if opts.File == "" {
+		InfoT("remote.fetching")
+		// most releases are in both, a range must list each once
+		for _, name := range g.remoteVersionNames() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	fromSpec, toSpec, isRange := strings.Cut(spec, "..")
+	if !isRange {
+		toSpec = fromSpec
+	}
+	to, err := releaseVersion(toSpec, names)
+	if err != nil {
+		return nil, err
+	}
+	if !isRange {
+		n, ok := byVersion[to.String()]
+		if !ok {
+			return nil, errors.New(T("notes.no_entry", to.String()))
+		}
+		return []ReleaseNote{n}, nil
+	}
+	from, err := releaseVersion(fromSpec, names)
+	if err != nil {
+		return nil, err
+	}
+	if from.Compare(to) >= 0 {
+		return nil, errors.New(T("notes.bad_range", spec))
+	}
+
+	var result []ReleaseNote
+	for _, group := range groupVersions(names) {
+		for _, rv := range group.Versions {
+			v, _ := ParseVersion(rv.Version)
+			if v.Compare(from) <= 0 || v.Compare(to) > 0 {
+				continue
+			}
+			// prereleases are not part of the release history
+			if n, ok := byVersion[rv.Version]; ok {
+				result = append(result, n)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New(T("notes.none_in_range", spec))
+	}
+	return result, nil
+}
+
+// releaseVersion parses one end of a notes argument, which must be a known version
+func releaseVersion(s string, names []string) (Version, error) {
+	v, err := ParseVersion(strings.TrimPrefix(strings.TrimSpace(s), "go"))
+	if err != nil {
+		return v, err
+	}
+	if !Find(names, v.String()) {
+		return v, errors.New(T("notes.unknown", v.String()))
+	}
+	return v, nil
+}
+
+// releaseHistory reads opts.File or the release history page, which is
+// cached for cache.remote_ttl like the remote versions
+func (g *GoVM) releaseHistory(opts NotesOptions) ([]byte, error) {
+	if opts.File != "" {
+		//#nosec G304
+		data, err := os.ReadFile(opts.File)
+		traceFS("read", opts.File, err)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", T("notes.read_failed", opts.File), err)
+		}
+		return data, nil
+	}
+	if data, ok := g.readRemoteCache(releaseHistoryCache); ok {
+		return data, nil
+	}
+	InfoT("notes.fetching", releaseHistoryURL)
+	data, err := fetch(releaseHistoryURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", T("notes.read_failed", releaseHistoryURL), err)
+	}
+	g.writeRemoteCache(releaseHistoryCache, data)
+	return data, nil
+}
+
+// parseReleaseHistory reads the releases of the release history page in
+// the order of the page. A release starts with a paragraph or heading like
+// "go1.21.6 (released 2024-01-09) includes ...", the paragraphs after a
+// heading, e.g. the one of a major release, are added to its text.
+func parseReleaseHistory(data []byte) []ReleaseNote {
+	var notes []ReleaseNote
+	current := -1
+	for _, block := range releaseBlock.FindAllSubmatch(data, -1) {
+		heading := block[1][0] == 'h' || block[1][0] == 'H'
+		content := string(block[2])
+		text := htmlText(content)
+		m := releaseLine.FindStringSubmatch(text)
+		if m == nil {
+			if heading {
+				current = -1
+			} else if current >= 0 {
+				notes[current].add(content, text)
+			}
+			continue
+		}
+		v, err := ParseVersion(m[1])
+		if err != nil {
+			continue
+		}
+		notes = append(notes, ReleaseNote{Version: v.String(), Released: m[2]})
+		current = len(notes) - 1
+		notes[current].add(content, m[3])
+	}
+	return notes
+}
+
+// add appends a paragraph to the note, content is its HTML and text what it reads
+func (n *ReleaseNote) add(content, text string) {
+	if text == "" {
+		return
+	}
+	if n.Text != "" {
+		n.Text += " "
+	}
+	n.Text += text
+	if strings.Contains(strings.ToLower(text), "security") {
+		n.Security = true
+	}
+	for _, m := range htmlCode.FindAllStringSubmatch(content, -1) {
+		if pkg := htmlText(m[1]); !Find(n.Packages, pkg) {
+			n.Packages = append(n.Packages, pkg)
+		}
+	}
+	for _, m := range htmlLink.FindAllStringSubmatch(content, -1) {
+		if n.Issues == "" && strings.Contains(m[1], "milestone") {
+			n.Issues = html.UnescapeString(m[1])
+		}
+	}
+}
+
+// htmlText is the text of an HTML fragment on one line
+func htmlText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(htmlTag.ReplaceAllString(s, ""))), " ")
+}
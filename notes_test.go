@@ -0,0 +1,161 @@
+package govm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testReleaseHistory = `<h2 id="go1.22.0">go1.22.0 (released 2024-02-06)</h2>
+
+<p>
+Go 1.22.0 is a major release of Go.
+Read the <a href="/doc/go1.22">Go 1.22 Release Notes</a> for more information.
+</p>
+
+<h3 id="go1.21.minor">Minor revisions</h3>
+
+<p>
+go1.21.4 (released 2023-11-07) includes security fixes to the <code>path/filepath</code> package,
+as well as bug fixes to the linker, the runtime, the compiler, and the <code>go/types</code>,
+<code>net/http</code>, and <code>runtime/cgo</code> packages.
+See the <a href="https://github.com/golang/go/issues?q=milestone%3AGo1.21.4+label%3ACherryPickApproved">Go
+1.21.4 milestone</a> on our issue tracker for details.
+</p>
+
+<p>
+go1.21.5 (released 2023-12-05) includes security fixes to the go command, and the <code>net/http</code>
+and <code>path/filepath</code> packages, as well as bug fixes to the compiler, the go command,
+the runtime, and the <code>crypto/rand</code>, <code>net</code>, <code>os</code>, and
+<code>syscall</code> packages.
+See the <a href="https://github.com/golang/go/issues?q=milestone%3AGo1.21.5+label%3ACherryPickApproved">Go
+1.21.5 milestone</a> on our issue tracker for details.
+</p>
+
+<p>
+go1.21.6 (released 2024-01-09) includes fixes to the compiler, the runtime, and the
+<code>crypto/tls</code>, <code>maps</code>, and <code>runtime/pprof</code> packages.
+See the <a href="https://github.com/golang/go/issues?q=milestone%3AGo1.21.6+label%3ACherryPickApproved">Go
+1.21.6 milestone</a> on our issue tracker for details.
+</p>
+
+<h2 id="go1.21.0">go1.21.0 (released 2023-08-08)</h2>
+
+<p>
+Go 1.21.0 is a major release of Go.
+</p>
+
+<h2 id="go1.16">go1.16 (released 2021-02-16)</h2>
+
+<p>
+Go 1.16 is a major release of Go &amp; friends.
+</p>
+`
+
+func TestParseReleaseHistory(t *testing.T) {
+	notes := parseReleaseHistory([]byte(testReleaseHistory))
+	var versions []string
+	for _, n := range notes {
+		versions = append(versions, n.Version)
+	}
+	if want := []string{"1.22.0", "1.21.4", "1.21.5", "1.21.6", "1.21.0", "1.16"}; !reflect.DeepEqual(versions, want) {
+		t.Fatalf("versions = %v, want %v", versions, want)
+	}
+
+	want := ReleaseNote{
+		Version:  "1.21.6",
+		Released: "2024-01-09",
+		Packages: []string{"crypto/tls", "maps", "runtime/pprof"},
+		Text: "includes fixes to the compiler, the runtime, and the crypto/tls, maps, and runtime/pprof packages. " +
+			"See the Go 1.21.6 milestone on our issue tracker for details.",
+		Issues: "https://github.com/golang/go/issues?q=milestone%3AGo1.21.6+label%3ACherryPickApproved",
+	}
+	if !reflect.DeepEqual(notes[3], want) {
+		t.Errorf("note = %+v, want %+v", notes[3], want)
+	}
+	if !notes[1].Security || !notes[2].Security || notes[0].Security {
+		t.Errorf("security = %v %v %v", notes[0].Security, notes[1].Security, notes[2].Security)
+	}
+	if got := notes[2].Packages; !reflect.DeepEqual(got, []string{"net/http", "path/filepath", "crypto/rand", "net", "os", "syscall"}) {
+		t.Errorf("packages = %v", got)
+	}
+	// the paragraph after the heading of a major release is its text
+	if notes[0].Text != "Go 1.22.0 is a major release of Go. Read the Go 1.22 Release Notes for more information." {
+		t.Errorf("text = %q", notes[0].Text)
+	}
+	if notes[5].Text != "Go 1.16 is a major release of Go & friends." {
+		t.Errorf("text = %q", notes[5].Text)
+	}
+}
+
+func TestReleaseNotes(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "release.html")
+	if err := os.WriteFile(file, []byte(testReleaseHistory), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := NotesOptions{File: file}
+
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"1.21.6", []string{"1.21.6"}},
+		{"go1.16.0", []string{"1.16"}},
+		{"1.21.4..1.21.6", []string{"1.21.5", "1.21.6"}},
+		{"1.21.0..1.22.0", []string{"1.21.4", "1.21.5", "1.21.6", "1.22.0"}},
+	}
+	for _, tt := range tests {
+		notes, err := g.ReleaseNotes(tt.spec, opts)
+		if err != nil {
+			t.Errorf("%s: %v", tt.spec, err)
+			continue
+		}
+		var got []string
+		for _, n := range notes {
+			got = append(got, n.Version)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"1.21.6..1.21.4", "1.21.7", "1.21.4..1.21.9", "1.21.4..", "latest"} {
+		if _, err := g.ReleaseNotes(spec, opts); err == nil {
+			t.Errorf("%s: no error", spec)
+		}
+	}
+
+	// the published versions add the releases without an entry yet
+	t.Setenv("GOVM_CACHE_REMOTE_TTL", "0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testReleaseHistory))
+	}))
+	defer server.Close()
+	defer func(url string) { releaseHistoryURL = url }(releaseHistoryURL)
+	releaseHistoryURL = server.URL
+	defer func(tags map[string][]string) { githubTags = tags }(githubTags)
+	githubTags = map[string][]string{"golang/go": {"go1.21.4", "go1.21.5", "go1.21.6", "go1.21.7", "go1.22rc1", "go1.22.0"}}
+
+	notes, err := g.ReleaseNotes("1.21.4..1.22.0", NotesOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, n := range notes {
+		got = append(got, n.Version)
+	}
+	if want := []string{"1.21.5", "1.21.6", "1.22.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("remote range = %v, want %v", got, want)
+	}
+	if _, err := g.ReleaseNotes("1.21.7", NotesOptions{}); err == nil {
+		t.Error("a release without an entry has notes")
+	}
+}
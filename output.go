@@ -0,0 +1,396 @@
+package govm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const versionsPerLine = 6
+
+// PrintInstalledVersions writes one version per line and highlights the active one
+func PrintInstalledVersions(w io.Writer, versions []InstalledVersion) {
+	current := ""
+	flagged := false
+	for _, v := range versions {
+		name := v.Version
+		if v.Store == StoreSystem {
+			name += T("list.system")
+		}
+		if len(v.Aliases) > 0 {
+			name += T("list.aliases", strings.Join(v.Aliases, ", "))
+		}
+		marker := ""
+		if v.EOL || len(v.Vulns) > 0 {
+			flagged = true
+			marker = reporter.Sprint(ColorError, T("list.audit_marker"))
+		}
+		if v.Active {
+			current = v.Version
+			fmt.Fprintln(w, reporter.Sprint(ColorSuccess, name+"*")+marker)
+		} else {
+			fmt.Fprintln(w, name+marker)
+		}
+	}
+
+	if current != "" || flagged {
+		fmt.Fprintln(w)
+	}
+	if current != "" {
+		fmt.Fprint(w, T("list.current", current))
+	}
+	if flagged {
+		fmt.Fprint(w, T("list.audit_hint"))
+	}
+}
+
+// PrintInstalledVersionsLong writes every version followed by its size on disk,
+// receipt, last use and the projects pinning it
+func PrintInstalledVersionsLong(w io.Writer, versions []InstalledVersion) {
+	const timeFormat = "2006-01-02 15:04"
+	for i, v := range versions {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		name := v.Version
+		if v.Store == StoreSystem {
+			name += T("list.system")
+		}
+		if v.Active {
+			fmt.Fprintln(w, reporter.Sprint(ColorSuccess, name+"*"))
+		} else {
+			fmt.Fprintln(w, reporter.Sprint(ColorMajorVersion, name))
+		}
+		fmt.Fprint(w, T("list.long.path", v.Path))
+		fmt.Fprint(w, T("list.long.size", FormatBytes(v.SizeBytes)))
+		fmt.Fprint(w, T("list.long.installed", v.InstalledAt.Local().Format(timeFormat)))
+		switch {
+		case v.Import != "":
+			fmt.Fprint(w, T("list.long.imported", v.Source, v.Import))
+		case v.Source != "":
+			fmt.Fprint(w, T("list.long.source", v.Source))
+			fmt.Fprint(w, T("list.long.mirror", v.Mirror))
+		}
+		if v.SHA256 != "" {
+			fmt.Fprint(w, T("list.long.sha256", v.SHA256))
+		}
+		if v.LastUsed == nil {
+			fmt.Fprint(w, T("list.long.last_used", T("list.long.never")))
+		} else {
+			fmt.Fprint(w, T("list.long.last_used", v.LastUsed.Local().Format(timeFormat)))
+		}
+		for _, pin := range v.PinnedBy {
+			fmt.Fprint(w, T("list.long.pinned_by", pin))
+		}
+		if v.EOL {
+			fmt.Fprint(w, reporter.Sprint(ColorError, T("list.long.eol")))
+		}
+		if len(v.Vulns) > 0 {
+			fmt.Fprint(w, reporter.Sprint(ColorError, T("list.long.vulns", strings.Join(v.Vulns, ", "))))
+		}
+	}
+}
+
+// PrintExplanation writes the decision chain of e followed by its notes
+func PrintExplanation(w io.Writer, e Explanation) {
+	version := e.Version
+	switch {
+	case version == "":
+		version = T("explain.none")
+	case !e.Installed:
+		version = T("explain.not_installed", version)
+	}
+	fmt.Fprint(w, T("explain.version", version))
+	if e.Selector != "" && e.Selector != e.Version {
+		fmt.Fprint(w, T("explain.selector", e.Selector))
+	}
+	switch e.Origin {
+	case OriginGlobal:
+		fmt.Fprint(w, T("explain.origin.global"))
+	default:
+		fmt.Fprint(w, T("explain.origin."+e.Origin, e.OriginPath))
+	}
+	if e.Binary != "" {
+		fmt.Fprint(w, T("explain.binary", e.Binary))
+	}
+	if e.Origin != OriginGlobal && e.Global != "" {
+		fmt.Fprint(w, T("explain.global", e.Global))
+	}
+	if e.PathGo != "" {
+		fmt.Fprint(w, T("explain.path_go", e.PathGo))
+	}
+	PrintExplanationNotes(w, e)
+}
+
+// PrintExplanationNotes writes what keeps the go on PATH from being the
+// version of e
+func PrintExplanationNotes(w io.Writer, e Explanation) {
+	for _, shadow := range e.Shadowing {
+		reporter.Fprint(w, ColorInfo, T("explain.shadowed", shadow, e.CurrentBin))
+	}
+	if !e.OnPath {
+		reporter.Fprint(w, ColorInfo, T("explain.not_on_path", e.CurrentBin))
+	}
+	if e.Origin != OriginGlobal && e.Global != "" && e.Version != "" && e.Version != e.Global {
+		reporter.Fprint(w, ColorInfo, T("explain.not_global", e.Global, e.Version, e.Version))
+	}
+}
+
+// PrintProblems writes the problems found by doctor with their fixes
+func PrintProblems(w io.Writer, problems []Problem) {
+	if len(problems) == 0 {
+		fmt.Fprint(w, reporter.Sprint(ColorSuccess, T("doctor.ok")))
+		return
+	}
+	for _, p := range problems {
+		switch {
+		case p.Fixed:
+			fmt.Fprint(w, reporter.Sprint(ColorSuccess, T("doctor.fixed", p.Message)))
+			continue
+		case p.Severity == SeverityError:
+			fmt.Fprint(w, reporter.Sprint(ColorError, T("doctor.error", p.Message)))
+		default:
+			fmt.Fprint(w, reporter.Sprint(ColorInfo, T("doctor.warning", p.Message)))
+		}
+		fmt.Fprint(w, T("doctor.suggestion", p.Suggestion))
+	}
+}
+
+// PrintImportCandidates writes the Go installations found by ScanImports
+func PrintImportCandidates(w io.Writer, candidates []ImportCandidate) {
+	if len(candidates) == 0 {
+		fmt.Fprint(w, reporter.Sprint(ColorInfo, T("import.none")))
+		return
+	}
+	for _, c := range candidates {
+		if c.Installed {
+			fmt.Fprint(w, T("import.candidate_installed", c.Version, c.Path))
+		} else {
+			fmt.Fprint(w, T("import.candidate", c.Version, c.Path))
+		}
+	}
+}
+
+// PrintVersionGroups writes the versions of each minor version on an indented block,
+// six versions per line, with markInstalled marking the installed versions
+func PrintVersionGroups(w io.Writer, groups []VersionGroup, markInstalled bool) {
+	for _, group := range groups {
+		// 1.0 is printed as 1, the releases before 1.1 had no minor version
+		label := strings.TrimSuffix(group.Minor, ".0")
+		fmt.Fprint(w, reporter.Sprint(ColorMajorVersion, label))
+		fmt.Fprint(w, "\t")
+
+		for i, v := range group.Versions {
+			if i > 0 && i%versionsPerLine == 0 {
+				fmt.Fprint(w, "\n\t")
+			}
+			fmt.Fprint(w, remoteVersionLabel(v, markInstalled)+"  ")
+		}
+		fmt.Fprint(w, "\n\n")
+	}
+}
+
+// PrintVersionList writes the versions of groups one per line, with
+// markInstalled marking the installed versions
+func PrintVersionList(w io.Writer, groups []VersionGroup, markInstalled bool) {
+	for _, group := range groups {
+		for _, v := range group.Versions {
+			fmt.Fprintln(w, remoteVersionLabel(v, markInstalled))
+		}
+	}
+}
+
+func remoteVersionLabel(v RemoteVersion, markInstalled bool) string {
+	if !markInstalled || !v.Installed {
+		return v.Version
+	}
+	return reporter.Sprint(ColorSuccess, v.Version+T("remote.installed"))
+}
+
+// FormatBytes writes n with a binary unit, e.g. 1.5 MiB
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTP"[exp])
+}
+
+// PrintStoreStats writes the size of the store and the space it saves
+func PrintStoreStats(w io.Writer, s StoreStats) {
+	fmt.Fprint(w, T("store.stats.dir", s.Dir))
+	fmt.Fprint(w, T("store.stats.versions", s.Versions))
+	fmt.Fprint(w, T("store.stats.blobs", s.Blobs, FormatBytes(s.StoredBytes)))
+	fmt.Fprint(w, T("store.stats.linked", FormatBytes(s.LinkedBytes)))
+	fmt.Fprint(w, reporter.Sprint(ColorSuccess, T("store.stats.saved", FormatBytes(s.SavedBytes))))
+	if s.Unreferenced > 0 {
+		fmt.Fprint(w, T("store.stats.unreferenced", s.Unreferenced))
+	}
+}
+
+// PrintAliases writes every alias with its version, and its selector when
+// it is not the version itself
+func PrintAliases(w io.Writer, aliases []Alias) {
+	if len(aliases) == 0 {
+		fmt.Fprint(w, reporter.Sprint(ColorInfo, T("alias.none")))
+		return
+	}
+	for _, a := range aliases {
+		if a.Selector == a.Version {
+			fmt.Fprint(w, T("alias.line", a.Name, a.Version))
+		} else {
+			fmt.Fprint(w, T("alias.line_selector", a.Name, a.Version, a.Selector))
+		}
+	}
+}
+
+// PrintGoUpgrades writes the plan of upgrade-go, one minor version per block
+func PrintGoUpgrades(w io.Writer, plan []GoUpgrade) {
+	if len(plan) == 0 {
+		fmt.Fprint(w, reporter.Sprint(ColorInfo, T("upgrade_go.none")))
+		return
+	}
+	for _, u := range plan {
+		fmt.Fprint(w, reporter.Sprint(ColorMajorVersion, T("upgrade_go.step", u.Minor, strings.Join(u.Superseded, ", "), u.To)))
+		if u.Current {
+			fmt.Fprint(w, T("upgrade_go.current", u.To))
+		}
+		if len(u.Aliases) > 0 {
+			fmt.Fprint(w, T("upgrade_go.aliases", strings.Join(u.Aliases, ", ")))
+		}
+		for _, v := range u.Prune {
+			fmt.Fprint(w, T("upgrade_go.prune", v))
+		}
+	}
+}
+
+// PrintAuditReport writes where the findings come from and then every
+// installed version with its findings
+func PrintAuditReport(w io.Writer, report AuditReport) {
+	const timeFormat = "2006-01-02 15:04"
+	if len(report.Supported) < supportedMinors {
+		fmt.Fprint(w, T("audit.supported_unknown"))
+	} else {
+		fmt.Fprint(w, T("audit.supported", strings.Join(report.Supported, ", ")))
+	}
+	if db := report.VulnDB; db != nil {
+		fmt.Fprint(w, T("audit.vulndb", db.Source, db.FetchedAt.Local().Format(timeFormat), db.Entries))
+	} else {
+		fmt.Fprint(w, T("audit.no_vulndb"))
+	}
+	fmt.Fprintln(w)
+
+	affected := 0
+	for _, v := range report.Versions {
+		name := v.Version
+		if v.Store == StoreSystem {
+			name += T("list.system")
+		}
+		if v.Active {
+			name += "*"
+		}
+		if !v.Affected() {
+			fmt.Fprint(w, reporter.Sprint(ColorSuccess, T("audit.version_ok", name)))
+			continue
+		}
+		affected++
+		fmt.Fprint(w, reporter.Sprint(ColorError, T("audit.version", name)))
+		if v.EOL {
+			fmt.Fprint(w, T("audit.eol"))
+		}
+		for _, vuln := range v.Vulns {
+			id := vuln.ID
+			if len(vuln.Aliases) > 0 {
+				id += " (" + strings.Join(vuln.Aliases, ", ") + ")"
+			}
+			if vuln.Fixed == "" {
+				fmt.Fprint(w, T("audit.vuln_unfixed", id, vuln.Summary))
+			} else {
+				fmt.Fprint(w, T("audit.vuln", id, vuln.Summary, vuln.Fixed))
+			}
+		}
+	}
+	if affected == 0 {
+		fmt.Fprintln(w)
+		fmt.Fprint(w, reporter.Sprint(ColorSuccess, T("audit.ok")))
+	}
+}
+
+// PrintReleaseNotes writes the release history of every note
+func PrintReleaseNotes(w io.Writer, notes []ReleaseNote) {
+	for i, n := range notes {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, reporter.Sprint(ColorMajorVersion, T("notes.header", n.Version, n.Released)))
+		if n.Security {
+			fmt.Fprint(w, reporter.Sprint(ColorError, T("notes.security")))
+		}
+		fmt.Fprintln(w)
+		fmt.Fprint(w, T("notes.text", n.Text))
+		if n.Issues != "" {
+			fmt.Fprint(w, T("notes.issues", n.Issues))
+		}
+	}
+}
+
+// PrintPruneDecisions writes one line per version telling whether prune removes it and why
+func PrintPruneDecisions(w io.Writer, decisions []PruneDecision) {
+	if len(decisions) == 0 {
+		fmt.Fprint(w, T("prune.nothing"))
+		return
+	}
+	for _, d := range decisions {
+		var reason string
+		switch d.Reason {
+		case PrunePinned:
+			reason = T("prune.reason."+d.Reason, d.Pin)
+		case PruneAliased:
+			reason = T("prune.reason."+d.Reason, d.Alias)
+		default:
+			reason = T("prune.reason." + d.Reason)
+		}
+		if d.Remove {
+			fmt.Fprintf(w, "%s %-12s %s\n", reporter.Sprint(ColorError, T("prune.remove")), d.Version, reason)
+		} else {
+			fmt.Fprintf(w, "%s %-12s %s\n", reporter.Sprint(ColorSuccess, T("prune.keep")), d.Version, reason)
+		}
+	}
+}
+
+// PrintVerifyResults writes the state of every version followed by up to
+// limit damaged files of each kind, limit < 0 lists them all
+func PrintVerifyResults(w io.Writer, results []VerifyResult, limit int) {
+	for _, r := range results {
+		name := r.Version
+		if r.Store == StoreSystem {
+			name += T("list.system")
+		}
+		switch {
+		case r.NoManifest:
+			fmt.Fprint(w, reporter.Sprint(ColorInfo, T("verify.no_manifest", name, r.Version)))
+			continue
+		case r.OK():
+			fmt.Fprint(w, reporter.Sprint(ColorSuccess, T("verify.ok", name)))
+			continue
+		}
+		fmt.Fprint(w, reporter.Sprint(ColorError, T("verify.damaged", name, len(r.Missing), len(r.Modified), len(r.Extra))))
+		for _, kind := range []struct {
+			label string
+			paths []string
+		}{{"verify.missing", r.Missing}, {"verify.modified", r.Modified}, {"verify.extra", r.Extra}} {
+			for i, path := range kind.paths {
+				if limit >= 0 && i == limit {
+					fmt.Fprint(w, T("verify.more", len(kind.paths)-limit))
+					break
+				}
+				fmt.Fprintf(w, "    %s  %s\n", T(kind.label), path)
+			}
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package govm
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pinFile names the version a project is pinned to
+const pinFile = ".go-version"
+
+// PrunePolicy selects the installed versions prune keeps. A version is
+// removed when at least one keep policy is set and none keeps it, or when
+// it is a prerelease unused for longer than PrereleaseOlderThan or listed
+// in Superseded. The current version, pinned versions and the versions of
+// aliases are never removed.
+type PrunePolicy struct {
+	// KeepLatestPatch keeps the newest stable patch of every minor version
+	KeepLatestPatch bool
+	// KeepRecent keeps the versions used most recently
+	KeepRecent int
+	// KeepPinned are directories searched for projects pinning a version
+	// with .go-version or the toolchain line of go.mod
+	KeepPinned []string
+	// PrereleaseOlderThan removes the rc and beta versions not used for this long
+	PrereleaseOlderThan time.Duration
+	// Superseded removes these versions, the patches upgrade-go replaced.
+	// Only they are removed then, the keep policies only keep versions.
+	Superseded []string
+}
+
+// Reasons of a PruneDecision
+const (
+	PruneCurrent       = "current"
+	PrunePinned        = "pinned"
+	PruneAliased       = "aliased"
+	PruneLatestPatch   = "latest_patch"
+	PruneRecent        = "recent"
+	PruneOldPrerelease = "old_prerelease"
+	PruneUnkept        = "not_kept"
+	// PruneSuperseded is the reason of upgrade-go, see GoUpgradeOptions.Prune
+	PruneSuperseded = "superseded"
+)
+
+// PruneDecision tells whether prune removes an installed version and why
+type PruneDecision struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Remove  bool   `json:"remove"`
+	Reason  string `json:"reason"`
+	// Pin is the file pinning the version for PrunePinned
+	Pin string `json:"pin,omitempty"`
+	// Alias is the alias of the version for PruneAliased
+	Alias string `json:"alias,omitempty"`
+	// LastUsed is the last use, or the install time of a version never used
+	LastUsed time.Time `json:"last_used"`
+}
+
+func (p PrunePolicy) keeps() bool {
+	return p.KeepLatestPatch || p.KeepRecent > 0 || len(p.KeepPinned) > 0
+}
+
+// ParseAge parses durations like 720h or 30d
+func ParseAge(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, errors.New(T("prune.bad_age", s))
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.New(T("prune.bad_age", s))
+	}
+	return d, nil
+}
+
+// PlanPrune decides for every version of the target store, the user store
+// or the system store with --system, whether the policy removes it
+func (g *GoVM) PlanPrune(p PrunePolicy, now time.Time) ([]PruneDecision, error) {
+	if !p.keeps() && p.PrereleaseOlderThan == 0 && len(p.Superseded) == 0 {
+		return nil, errors.New(T("prune.no_policy"))
+	}
+	installed, err := g.InstalledVersions(false)
+	if err != nil {
+		return nil, err
+	}
+	target := StoreUser
+	if g.system {
+		target = StoreSystem
+	}
+	var versions []InstalledVersion
+	for _, v := range installed {
+		if v.Store == target {
+			versions = append(versions, v)
+		}
+	}
+
+	pins, err := findPins(p.KeepPinned)
+	if err != nil {
+		return nil, err
+	}
+	pinned := pinnedVersions(versions, pins)
+	latest := latestPatches(versions)
+	recent := mostRecent(versions, p.KeepRecent)
+	current := g.CurrentVersion()
+	aliases := g.aliasesByVersion()
+
+	decisions := make([]PruneDecision, 0, len(versions))
+	for _, v := range versions {
+		d := PruneDecision{Version: v.Version, Path: v.Path, LastUsed: lastUse(v)}
+		oldPrerelease := p.PrereleaseOlderThan > 0 && v.Stability == StabilityPrerelease &&
+			now.Sub(d.LastUsed) > p.PrereleaseOlderThan
+		switch {
+		case v.Version == current:
+			d.Reason = PruneCurrent
+		case len(pinned[v.Version]) > 0:
+			d.Reason, d.Pin = PrunePinned, pinned[v.Version][0]
+		case len(aliases[v.Version]) > 0:
+			d.Reason, d.Alias = PruneAliased, aliases[v.Version][0]
+		case Find(p.Superseded, v.Version):
+			d.Reason, d.Remove = PruneSuperseded, true
+		case oldPrerelease:
+			d.Reason, d.Remove = PruneOldPrerelease, true
+		case p.KeepLatestPatch && latest[v.Version]:
+			d.Reason = PruneLatestPatch
+		case recent[v.Version]:
+			d.Reason = PruneRecent
+		case p.keeps() && len(p.Superseded) == 0:
+			d.Reason, d.Remove = PruneUnkept, true
+		default:
+			continue
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, nil
+}
+
+// Prune removes the versions decided for removal, then the blobs of the
+// store nobody links to anymore
+func (g *GoVM) Prune(decisions []PruneDecision) error {
+	if g.system {
+		if err := g.checkSystemStore(); err != nil {
+			return err
+		}
+	}
+	current := g.CurrentVersion()
+	for _, d := range decisions {
+		if !d.Remove || d.Version == current {
+			continue
+		}
+		g.cleanVersionDir(d.Version)
+		g.forgetRemovedVersion(d.Version)
+		SuccessT("prune.removed", d.Version)
+	}
+	removed, freed, err := g.CollectGarbage()
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		InfoT("store.gc", removed, FormatBytes(freed))
+	}
+	return nil
+}
+
+// forgetRemovedVersion drops the last use of a version installed in no store
+// anymore. The record is kept per user, so the user store of the layout counts
+// with --system too.
+func (g *GoVM) forgetRemovedVersion(version string) {
+	for _, dir := range []string{g.layout.versionsDir(), g.systemVersionsDir} {
+		if _, err := os.Stat(filepath.Join(dir, version)); err == nil {
+			return
+		}
+	}
+	g.forgetVersion(version)
+}
+
+// latestPatches marks the newest stable version of every minor version
+func latestPatches(versions []InstalledVersion) map[string]bool {
+	newest := make(map[string]InstalledVersion)
+	newestVersion := make(map[string]Version)
+	for _, v := range versions {
+		if v.Stability != StabilityStable {
+			continue
+		}
+		gv, err := ParseVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		minor := gv.MinorVersion()
+		if n, ok := newestVersion[minor]; !ok || gv.Compare(n) > 0 {
+			newest[minor], newestVersion[minor] = v, gv
+		}
+	}
+	keep := make(map[string]bool, len(newest))
+	for _, v := range newest {
+		keep[v.Version] = true
+	}
+	return keep
+}
+
+// lastUse is the last use of v, or its install time when never used
+func lastUse(v InstalledVersion) time.Time {
+	if v.LastUsed == nil {
+		return v.InstalledAt
+	}
+	return *v.LastUsed
+}
+
+// mostRecent marks the n versions used last, the install time stands in
+// for versions never used
+func mostRecent(versions []InstalledVersion, n int) map[string]bool {
+	sorted := append([]InstalledVersion(nil), versions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return lastUse(sorted[i]).After(lastUse(sorted[j])) })
+	keep := make(map[string]bool, n)
+	for i := 0; i < n && i < len(sorted); i++ {
+		keep[sorted[i].Version] = true
+	}
+	return keep
+}
+
+// pinnedVersions maps the installed versions pinned by projects to the pin
+// files. A pin to a minor version like 1.17 keeps its newest installed patch.
+func pinnedVersions(versions []InstalledVersion, pins map[string][]string) map[string][]string {
+	pinned := make(map[string][]string)
+	installed := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		installed[v.Version] = true
+	}
+	latest := latestPatches(versions)
+	for pin, files := range pins {
+		if installed[pin] {
+			pinned[pin] = append(pinned[pin], files...)
+			continue
+		}
+		for _, v := range versions {
+			if latest[v.Version] && strings.HasPrefix(v.Version, pin+".") {
+				pinned[v.Version] = append(pinned[v.Version], files...)
+			}
+		}
+	}
+	for _, files := range pinned {
+		sort.Strings(files)
+	}
+	return pinned
+}
+
+// findPins walks dirs for .go-version files and go.mod toolchain lines and
+// returns the files pinning each version
+func findPins(dirs []string) (map[string][]string, error) {
+	pins := make(map[string][]string)
+	add := func(version, file string) {
+		version = normalizeVersion(strings.TrimPrefix(strings.TrimSpace(version), "go"))
+		if version != "" {
+			pins[version] = append(pins[version], file)
+		}
+	}
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			switch d.Name() {
+			case pinFile:
+				//#nosec G304
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				add(string(data), path)
+			case "go.mod":
+				if toolchain := goModToolchain(path); toolchain != "" {
+					add(toolchain, path)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pins, nil
+}
+
+// goModToolchain returns the version of the toolchain directive of a go.mod
+func goModToolchain(path string) string {
+	//#nosec G304
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "toolchain" {
+			return fields[1]
+		}
+	}
+	return ""
+}
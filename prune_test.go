@@ -0,0 +1,109 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanPrune(t *testing.T) {
+	home, projects := t.TempDir(), t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	for _, v := range []string{"1.16.1", "1.16.2", "1.17.5", "1.17.6", "1.18rc1", "1.18beta1"} {
+		if err := os.MkdirAll(filepath.Join(home, "versions", v, "go", "bin"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app := filepath.Join(projects, "app")
+	if err := os.MkdirAll(app, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(app, pinFile), []byte("1.16.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.17.5")
+
+	now := time.Now()
+	g.updateLastUsed(func(used map[string]time.Time) {
+		for _, v := range []string{"1.16.1", "1.16.2", "1.17.5", "1.17.6", "1.18rc1"} {
+			used[v] = now.Add(-90 * 24 * time.Hour)
+		}
+		used["1.18beta1"] = now.Add(-time.Hour)
+	})
+
+	if _, err := g.PlanPrune(PrunePolicy{}, now); err == nil {
+		t.Error("no policy accepted")
+	}
+
+	cases := []struct {
+		policy PrunePolicy
+		remove []string
+	}{
+		{PrunePolicy{KeepLatestPatch: true}, []string{"1.16.1", "1.18rc1", "1.18beta1"}},
+		{PrunePolicy{KeepLatestPatch: true, KeepPinned: []string{projects}}, []string{"1.18rc1", "1.18beta1"}},
+		{PrunePolicy{KeepLatestPatch: true, KeepRecent: 1}, []string{"1.16.1", "1.18rc1"}},
+		{PrunePolicy{PrereleaseOlderThan: 30 * 24 * time.Hour}, []string{"1.18rc1"}},
+		{PrunePolicy{KeepPinned: []string{projects}}, []string{"1.16.2", "1.17.6", "1.18rc1", "1.18beta1"}},
+	}
+	for _, c := range cases {
+		decisions, err := g.PlanPrune(c.policy, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var remove []string
+		for _, d := range decisions {
+			if d.Remove {
+				remove = append(remove, d.Version)
+			}
+			if d.Version == "1.17.5" && (d.Remove || d.Reason != PruneCurrent) {
+				t.Errorf("%+v: current version %+v", c.policy, d)
+			}
+		}
+		if len(remove) != len(c.remove) {
+			t.Errorf("%+v removes %v, want %v", c.policy, remove, c.remove)
+			continue
+		}
+		for _, v := range c.remove {
+			if !Find(remove, v) {
+				t.Errorf("%+v removes %v, want %v", c.policy, remove, c.remove)
+			}
+		}
+	}
+
+	decisions, err := g.PlanPrune(PrunePolicy{KeepPinned: []string{projects}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Prune(decisions); err != nil {
+		t.Fatal(err)
+	}
+	versions, _ := g.InstalledVersions(false)
+	if len(versions) != 2 {
+		t.Errorf("left %+v", versions)
+	}
+	if _, ok := g.lastUsed()["1.18rc1"]; ok {
+		t.Error("last use of a removed version kept")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for s, want := range map[string]time.Duration{"30d": 30 * 24 * time.Hour, "36h": 36 * time.Hour, "0d": 0} {
+		if got, err := ParseAge(s); err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v", s, got, err)
+		}
+	}
+	for _, s := range []string{"", "d", "-1d", "1w"} {
+		if _, err := ParseAge(s); err == nil {
+			t.Errorf("ParseAge(%q) accepted", s)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package govm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const receiptFile = "receipt.json"
+
+// Receipt is written next to the extracted go tree of every version govm installs
+type Receipt struct {
+	Version string `json:"version"`
+	Source  string `json:"source"`
+	Mirror  string `json:"mirror"`
+	// SHA256 is the checksum of the downloaded archive
+	SHA256 string `json:"sha256,omitempty"`
+	// Import is how an installation found on the machine was imported,
+	// ImportCopy, ImportMove or ImportLink, with Source its GOROOT
+	Import      string    `json:"import,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+func (g *GoVM) receiptPath(version string) string {
+	return filepath.Join(g.getVersionDir(version), receiptFile)
+}
+
+func (g *GoVM) readReceipt(version string) (Receipt, error) {
+	return readReceiptFile(g.receiptPath(version))
+}
+
+func readReceiptFile(path string) (Receipt, error) {
+	var r Receipt
+	//#nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+func (g *GoVM) writeReceipt(r Receipt) error {
+	return writeReceiptFile(g.getVersionDir(r.Version), r)
+}
+
+// writeReceiptFile writes r into dir, which may still be a staging dir
+func writeReceiptFile(dir string, r Receipt) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, receiptFile)
+	err = os.WriteFile(path, data, 0644)
+	traceFS("write", path, err)
+	return err
+}
@@ -0,0 +1,185 @@
+package govm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// downloadsURL lists every published Go release with its archives
+var downloadsURL = "https://go.dev/dl/?mode=json&include=all"
+
+const downloadsCache = "downloads.json"
+
+// minorOnly matches a minor version without patch, e.g. 1.18
+var minorOnly = regexp.MustCompile(`^\d+\.\d+$`)
+
+// RemoteFilter selects remote versions, the zero value selects them all
+type RemoteFilter struct {
+	// Stable and Prerelease keep the versions of that stability only
+	Stable     bool
+	Prerelease bool
+	// Since keeps the versions from a minor version on, e.g. 1.18 with its
+	// prereleases, or from a version on, e.g. 1.18.3
+	Since string
+	// Minor keeps the versions of one minor version, e.g. 1.21
+	Minor string
+	// LatestPerMinor keeps the last version of every minor version the other
+	// filters leave
+	LatestPerMinor bool
+	// AvailableFor keeps the versions with an archive for a platform, e.g. linux/arm64
+	AvailableFor string
+}
+
+// Validate checks the values of f before anything is fetched
+func (f RemoteFilter) Validate() error {
+	if f.Stable && f.Prerelease {
+		return errors.New(T("remote.stable_prerelease"))
+	}
+	for _, s := range []string{f.Since, f.Minor} {
+		if s == "" {
+			continue
+		}
+		if _, err := ParseVersion(s); err != nil {
+			return err
+		}
+	}
+	if f.AvailableFor != "" {
+		if _, _, ok := splitPlatform(f.AvailableFor); !ok {
+			return errors.New(T("remote.bad_platform", f.AvailableFor))
+		}
+	}
+	return nil
+}
+
+// FilterRemoteVersions fetches the available versions and keeps the ones
+// selected by f, grouped like RemoteVersions, marking the installed ones
+func (g *GoVM) FilterRemoteVersions(f RemoteFilter) ([]VersionGroup, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	InfoT("remote.fetching")
+	names := g.remoteVersionNames()
+	if f.AvailableFor != "" {
+		available, err := g.platformVersions(f.AvailableFor)
+		if err != nil {
+			return nil, err
+		}
+		var kept []string
+		for _, name := range names {
+			if available[name] {
+				kept = append(kept, name)
+			}
+		}
+		names = kept
+	}
+	groups := filterVersionGroups(groupVersions(names), f)
+
+	installed := make(map[string]bool)
+	if versions, err := g.InstalledVersions(false); err == nil {
+		for _, v := range versions {
+			installed[v.Version] = true
+		}
+	}
+	for i := range groups {
+		for j := range groups[i].Versions {
+			groups[i].Versions[j].Installed = installed[groups[i].Versions[j].Version]
+		}
+	}
+	return groups, nil
+}
+
+// filterVersionGroups keeps the versions of groups selected by the stability,
+// since, minor and latest filters of f, dropping the groups left empty
+func filterVersionGroups(groups []VersionGroup, f RemoteFilter) []VersionGroup {
+	var since Version
+	if f.Since != "" {
+		since, _ = ParseVersion(f.Since)
+	}
+	minor := ""
+	if f.Minor != "" {
+		v, _ := ParseVersion(f.Minor)
+		minor = v.MinorVersion()
+	}
+
+	var kept []VersionGroup
+	for _, group := range groups {
+		if minor != "" && group.Minor != minor {
+			continue
+		}
+		var versions []RemoteVersion
+		for _, rv := range group.Versions {
+			if f.Stable && rv.Stability != StabilityStable || f.Prerelease && rv.Stability == StabilityStable {
+				continue
+			}
+			if f.Since != "" {
+				v, _ := ParseVersion(rv.Version)
+				// 1.18 starts with the prereleases of 1.18
+				fromMinor := minorOnly.MatchString(f.Since) && v.MinorVersion() == since.MinorVersion()
+				if v.Compare(since) < 0 && !fromMinor {
+					continue
+				}
+			}
+			versions = append(versions, rv)
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		if f.LatestPerMinor {
+			versions = versions[len(versions)-1:]
+		}
+		kept = append(kept, VersionGroup{Minor: group.Minor, Versions: versions})
+	}
+	return kept
+}
+
+// platformVersions are the versions with an archive published for platform,
+// the list of downloads is cached for cache.remote_ttl like the remote versions
+func (g *GoVM) platformVersions(platform string) (map[string]bool, error) {
+	goos, goarch, _ := splitPlatform(platform)
+	data, cached := g.readRemoteCache(downloadsCache)
+	if !cached {
+		var err error
+		if data, err = fetch(downloadsURL); err != nil {
+			return nil, fmt.Errorf("%s: %w", T("remote.downloads_failed"), err)
+		}
+	}
+	var releases []struct {
+		Version string `json:"version"`
+		Files   []struct {
+			OS   string `json:"os"`
+			Arch string `json:"arch"`
+			Kind string `json:"kind"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("%s: %w", T("remote.downloads_failed"), err)
+	}
+	if !cached {
+		g.writeRemoteCache(downloadsCache, data)
+	}
+
+	available := make(map[string]bool)
+	for _, r := range releases {
+		for _, f := range r.Files {
+			if f.Kind == "archive" && f.OS == goos && f.Arch == goarch {
+				available[normalizeVersion(strings.TrimPrefix(r.Version, "go"))] = true
+				break
+			}
+		}
+	}
+	return available, nil
+}
+
+// splitPlatform splits linux/arm64, or linux-arm64 as in the archive names,
+// into the os and the architecture
+func splitPlatform(platform string) (string, string, bool) {
+	sep := "/"
+	if !strings.Contains(platform, sep) {
+		sep = "-"
+	}
+	goos, goarch, ok := strings.Cut(platform, sep)
+	return goos, goarch, ok && goos != "" && goarch != "" && !strings.ContainsAny(goarch, "/-")
+}
@@ -0,0 +1,107 @@
+package govm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilterVersionGroups(t *testing.T) {
+	groups := groupVersions([]string{
+		"1.17.6", "1.18beta1", "1.18rc1", "1.18", "1.18.1",
+		"1.21rc2", "1.21.0", "1.21.5", "1.21.6", "1.22rc1",
+	})
+	tests := []struct {
+		name   string
+		filter RemoteFilter
+		want   []string
+	}{
+		{"all", RemoteFilter{}, []string{"1.17.6", "1.18beta1", "1.18rc1", "1.18", "1.18.1", "1.21rc2", "1.21.0", "1.21.5", "1.21.6", "1.22rc1"}},
+		{"stable", RemoteFilter{Stable: true}, []string{"1.17.6", "1.18", "1.18.1", "1.21.0", "1.21.5", "1.21.6"}},
+		{"prerelease", RemoteFilter{Prerelease: true}, []string{"1.18beta1", "1.18rc1", "1.21rc2", "1.22rc1"}},
+		{"since minor", RemoteFilter{Since: "1.18"}, []string{"1.18beta1", "1.18rc1", "1.18", "1.18.1", "1.21rc2", "1.21.0", "1.21.5", "1.21.6", "1.22rc1"}},
+		{"since version", RemoteFilter{Since: "1.21.5"}, []string{"1.21.5", "1.21.6", "1.22rc1"}},
+		{"minor", RemoteFilter{Minor: "1.21"}, []string{"1.21rc2", "1.21.0", "1.21.5", "1.21.6"}},
+		{"latest", RemoteFilter{LatestPerMinor: true}, []string{"1.17.6", "1.18.1", "1.21.6", "1.22rc1"}},
+		{"latest stable since", RemoteFilter{Stable: true, LatestPerMinor: true, Since: "1.18"}, []string{"1.18.1", "1.21.6"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, group := range filterVersionGroups(groups, tt.filter) {
+			if len(group.Versions) == 0 {
+				t.Errorf("%s: empty group %s", tt.name, group.Minor)
+			}
+			for _, v := range group.Versions {
+				got = append(got, v.Version)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteFilterValidate(t *testing.T) {
+	for _, f := range []RemoteFilter{
+		{Stable: true, Prerelease: true},
+		{Since: "latest"},
+		{Minor: "1.x"},
+		{AvailableFor: "linux"},
+		{AvailableFor: "linux/arm64/v8"},
+	} {
+		if f.Validate() == nil {
+			t.Errorf("%+v is valid", f)
+		}
+	}
+	for _, f := range []RemoteFilter{{}, {AvailableFor: "linux/arm64"}, {AvailableFor: "darwin-amd64"}, {Since: "1.18", Minor: "1.21"}} {
+		if err := f.Validate(); err != nil {
+			t.Errorf("%+v: %v", f, err)
+		}
+	}
+}
+
+func TestFilterRemoteVersions(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	t.Setenv("GOVM_CACHE_REMOTE_TTL", "0")
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "versions", "1.21.5", "go", "bin"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(tags map[string][]string) { githubTags = tags }(githubTags)
+	githubTags = map[string][]string{"golang/go": {"go1.20.1", "go1.21.5", "go1.21.6", "go1.22rc1"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"version": "go1.22rc1", "files": [{"os": "linux", "arch": "arm64", "kind": "archive"}]},
+			{"version": "go1.21.6", "files": [{"os": "linux", "arch": "arm64", "kind": "source"}, {"os": "darwin", "arch": "arm64", "kind": "archive"}]},
+			{"version": "go1.21.5", "files": [{"os": "linux", "arch": "arm64", "kind": "archive"}]}
+		]`))
+	}))
+	defer server.Close()
+	defer func(url string) { downloadsURL = url }(downloadsURL)
+	downloadsURL = server.URL
+
+	groups, err := g.FilterRemoteVersions(RemoteFilter{AvailableFor: "linux/arm64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VersionGroup{
+		{Minor: "1.21", Versions: []RemoteVersion{{Version: "1.21.5", Stability: StabilityStable, Installed: true}}},
+		{Minor: "1.22", Versions: []RemoteVersion{{Version: "1.22rc1", Stability: StabilityPrerelease}}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups = %+v, want %+v", groups, want)
+	}
+
+	if groups := g.RemoteVersions(); len(groups) != 3 || !groups[1].Versions[0].Installed || groups[1].Versions[1].Installed {
+		t.Errorf("remote versions = %+v", groups)
+	}
+}
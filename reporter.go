@@ -0,0 +1,149 @@
+package govm
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Level controls how much a Reporter prints
+type Level int
+
+const (
+	// LevelQuiet prints results and errors only
+	LevelQuiet Level = iota
+	// LevelNormal adds status messages and progress bars
+	LevelNormal
+	// LevelVerbose adds details such as download urls and paths
+	LevelVerbose
+)
+
+// Reporter is where every user-facing message of govm goes.
+// Results and status messages are written to Out, errors and progress bars to Err.
+type Reporter struct {
+	Out   io.Writer
+	Err   io.Writer
+	Level Level
+	// Color enables ANSI colors on Out
+	Color bool
+	// ErrColor enables ANSI colors on Err
+	ErrColor bool
+	// Progress enables progress bars for downloads
+	Progress bool
+	// noColor disables colors on any other writer
+	noColor bool
+}
+
+var reporter = NewReporter(os.Stdout, os.Stderr, LevelNormal, false)
+
+func init() {
+	// whether to colorize is decided by the reporter, not by the color package
+	for _, c := range []*color.Color{ColorMajorVersion, ColorSuccess, ColorInfo, ColorError} {
+		c.EnableColor()
+	}
+}
+
+// NewReporter for out and err, colors and progress bars are only enabled
+// when the corresponding stream is a terminal, NO_COLOR is unset and noColor is false
+func NewReporter(out, err io.Writer, level Level, noColor bool) *Reporter {
+	noColor = noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+	return &Reporter{
+		Out:      out,
+		Err:      err,
+		Level:    level,
+		Color:    !noColor && isTerminal(out),
+		ErrColor: !noColor && isTerminal(err),
+		Progress: level >= LevelNormal && isTerminal(err),
+		noColor:  noColor,
+	}
+}
+
+// SetReporter replaces the reporter used by the package level output helpers
+func SetReporter(r *Reporter) {
+	reporter = r
+}
+
+// CurrentReporter returns the reporter used by the package level output helpers
+func CurrentReporter() *Reporter {
+	return reporter
+}
+
+// IsTerminal reports whether w is a terminal
+func IsTerminal(w io.Writer) bool {
+	return isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// Sprint formats a with c when colors are enabled
+func (r *Reporter) Sprint(c *color.Color, a ...interface{}) string {
+	if !r.Color {
+		return fmt.Sprint(a...)
+	}
+	return c.Sprint(a...)
+}
+
+// Fprint writes a to w, formatted with c when colors are enabled for w
+func (r *Reporter) Fprint(w io.Writer, c *color.Color, a ...interface{}) {
+	s := fmt.Sprint(a...)
+	if r.colorFor(w) {
+		s = c.Sprint(s)
+	}
+	_, _ = io.WriteString(w, s)
+}
+
+// colorFor tells whether to colorize what is written to w, writers other
+// than Out and Err are colored when they are a terminal
+func (r *Reporter) colorFor(w io.Writer) bool {
+	switch w {
+	case r.Out:
+		return r.Color
+	case r.Err:
+		return r.ErrColor
+	}
+	return !r.noColor && isTerminal(w)
+}
+
+func (r *Reporter) print(w io.Writer, min Level, c *color.Color, s string) {
+	if r.Level < min {
+		return
+	}
+	if r.colorFor(w) {
+		s = c.Sprint(s)
+	}
+	_, _ = io.WriteString(w, s)
+}
+
+// Resultf writes to Out regardless of the level
+func (r *Reporter) Resultf(c *color.Color, format string, a ...interface{}) {
+	r.print(r.Out, LevelQuiet, c, fmt.Sprintf(format, a...))
+}
+
+// Successf writes a success message to Out
+func (r *Reporter) Successf(format string, a ...interface{}) {
+	r.print(r.Out, LevelNormal, ColorSuccess, fmt.Sprintf(format, a...))
+}
+
+// Infof writes a status message to Out
+func (r *Reporter) Infof(format string, a ...interface{}) {
+	r.print(r.Out, LevelNormal, ColorInfo, fmt.Sprintf(format, a...))
+}
+
+// Debugf writes a detail message to Err in verbose mode
+func (r *Reporter) Debugf(format string, a ...interface{}) {
+	r.print(r.Err, LevelVerbose, ColorInfo, fmt.Sprintf(format, a...))
+}
+
+// Errorf writes an error message to Err regardless of the level
+func (r *Reporter) Errorf(format string, a ...interface{}) {
+	r.print(r.Err, LevelQuiet, ColorError, fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,57 @@
+package govm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReporterLevels(t *testing.T) {
+	cases := []struct {
+		level  Level
+		out    string
+		errOut string
+	}{
+		{LevelQuiet, "result\n", "error\n"},
+		{LevelNormal, "result\ninfo\n", "error\n"},
+		{LevelVerbose, "result\ninfo\n", "debug\nerror\n"},
+	}
+	for _, c := range cases {
+		var out, errOut bytes.Buffer
+		r := NewReporter(&out, &errOut, c.level, false)
+		r.Resultf(ColorSuccess, "result\n")
+		r.Infof("info\n")
+		r.Debugf("debug\n")
+		r.Errorf("error\n")
+		if out.String() != c.out || errOut.String() != c.errOut {
+			t.Errorf("level %d: got out=%q err=%q, want out=%q err=%q", c.level, out.String(), errOut.String(), c.out, c.errOut)
+		}
+	}
+}
+
+func TestReporterNoColorForNonTerminal(t *testing.T) {
+	var out bytes.Buffer
+	r := NewReporter(&out, &out, LevelNormal, false)
+	if r.Color || r.ErrColor || r.Progress {
+		t.Fatalf("colors and progress bars must be disabled for non terminals: %+v", r)
+	}
+	r.Successf("ok")
+	if out.String() != "ok" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestReporterFprint(t *testing.T) {
+	var out, errOut, other bytes.Buffer
+	r := NewReporter(&out, &errOut, LevelNormal, false)
+	// colors follow the writer, not the stream Sprint is made for
+	r.Color = true
+	for _, w := range []*bytes.Buffer{&out, &errOut, &other} {
+		r.Fprint(w, ColorInfo, "note\n")
+	}
+	if out.String() != ColorInfo.Sprint("note\n") {
+		t.Errorf("out = %q, want colored", out.String())
+	}
+	if errOut.String() != "note\n" || other.String() != "note\n" {
+		t.Errorf("err = %q, other = %q, want plain", errOut.String(), other.String())
+	}
+}
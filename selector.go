@@ -0,0 +1,204 @@
+package govm
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Selector picks one Go version out of the published ones, see ParseSelector
+type Selector struct {
+	raw string
+	// exact is set for a version name, which is used as is
+	exact string
+	// constraints must all hold, which no constraint at all does
+	constraints []constraint
+	// prerelease lets rc and beta versions match
+	prerelease bool
+	// back selects the newest release back minor versions before the newest one
+	back int
+	// minor limits the candidates to a minor version, it is "" for the latest ones
+	minor string
+}
+
+type constraint struct {
+	op string
+	v  Version
+}
+
+func (c constraint) matches(v Version) bool {
+	d := v.Compare(c.v)
+	switch c.op {
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case "!=":
+		return d != 0
+	default:
+		return d == 0
+	}
+}
+
+var (
+	minorSelectorPattern = regexp.MustCompile(`^(\d+\.\d+)(?:\.?x|@latest|@dev-latest)$`)
+	latestBackPattern    = regexp.MustCompile(`^latest-(\d+)$`)
+	constraintPattern    = regexp.MustCompile(`^(>=|<=|!=|>|<|=|~|\^)?(.+)$`)
+	opSpacePattern       = regexp.MustCompile(`(>=|<=|!=|>|<|=|~|\^)\s+`)
+)
+
+// ParseSelector parses a version selector:
+//
+//	1.21.3, 1.16, 1.21rc2       that exact version
+//	latest, stable              the newest release
+//	dev-latest                  the newest version, including rc and beta
+//	oldstable, latest-N         the newest release of the previous, or Nth previous, minor version
+//	1.21.x, 1.21x, 1.21@latest  the newest release of 1.21
+//	1.21@dev-latest             the newest version of 1.21, including rc and beta
+//	>=1.20 <1.22, >=1.20,<1.22  the newest release satisfying every comparison
+//	~1.21, ~1.21.3              the newest 1.21 release, at least 1.21.3
+//	^1.21.3                     the newest 1.x release, at least 1.21.3
+func ParseSelector(s string) (Selector, error) {
+	sel := Selector{raw: s}
+	s = strings.TrimSpace(s)
+	switch s {
+	case "":
+		return sel, errors.New(T("selector.empty"))
+	case "latest", "stable":
+		return sel, nil
+	case "dev-latest":
+		sel.prerelease = true
+		return sel, nil
+	case "oldstable":
+		sel.back = 1
+		return sel, nil
+	}
+	if m := latestBackPattern.FindStringSubmatch(s); m != nil {
+		sel.back, _ = strconv.Atoi(m[1])
+		return sel, nil
+	}
+	if m := minorSelectorPattern.FindStringSubmatch(s); m != nil {
+		sel.minor = m[1]
+		sel.prerelease = strings.HasSuffix(s, "@dev-latest")
+		return sel, nil
+	}
+	if _, err := ParseVersion(s); err == nil {
+		sel.exact = normalizeVersion(s)
+		return sel, nil
+	}
+
+	fields := strings.FieldsFunc(opSpacePattern.ReplaceAllString(s, "$1"), func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	for _, field := range fields {
+		m := constraintPattern.FindStringSubmatch(field)
+		v, err := ParseVersion(m[2])
+		if err != nil {
+			return sel, errors.New(T("selector.invalid", s, field))
+		}
+		switch m[1] {
+		case "~":
+			// the same minor version
+			sel.constraints = append(sel.constraints,
+				constraint{">=", v},
+				constraint{"<", Version{Major: v.Major, Minor: v.Minor + 1, Pre: "alpha"}})
+		case "^":
+			// the same major version
+			sel.constraints = append(sel.constraints,
+				constraint{">=", v},
+				constraint{"<", Version{Major: v.Major + 1, Pre: "alpha"}})
+		default:
+			sel.constraints = append(sel.constraints, constraint{m[1], v})
+		}
+		// naming a prerelease lets prereleases match
+		sel.prerelease = sel.prerelease || v.Prerelease()
+	}
+	return sel, nil
+}
+
+// String is the selector as it was given
+func (s Selector) String() string {
+	return s.raw
+}
+
+// Exact returns the version an exact selector names, which needs no list
+// of published versions to resolve
+func (s Selector) Exact() (string, bool) {
+	return s.exact, s.exact != ""
+}
+
+// Resolve picks the newest of versions the selector matches
+func (s Selector) Resolve(versions []string) (string, error) {
+	if s.exact != "" {
+		for _, v := range versions {
+			if v == s.exact {
+				return v, nil
+			}
+		}
+		return "", errors.New(T("selector.no_match", s.raw))
+	}
+
+	type candidate struct {
+		name string
+		v    Version
+	}
+	var candidates []candidate
+	for _, name := range versions {
+		v, err := ParseVersion(name)
+		if err != nil || (v.Prerelease() && !s.prerelease) {
+			continue
+		}
+		if s.minor != "" && v.MinorVersion() != s.minor {
+			continue
+		}
+		matches := true
+		for _, c := range s.constraints {
+			matches = matches && c.matches(v)
+		}
+		if matches {
+			candidates = append(candidates, candidate{name, v})
+		}
+	}
+
+	if s.back > 0 {
+		// the minor versions with a release, newest first
+		seen := make(map[Version]bool)
+		var minors []Version
+		for _, c := range candidates {
+			m := Version{Major: c.v.Major, Minor: c.v.Minor}
+			if !seen[m] {
+				seen[m] = true
+				minors = append(minors, m)
+			}
+		}
+		sort.Slice(minors, func(i, j int) bool { return minors[i].Compare(minors[j]) > 0 })
+		if s.back >= len(minors) {
+			return "", errors.New(T("selector.no_match", s.raw))
+		}
+		want := minors[s.back]
+		filtered := candidates[:0]
+		for _, c := range candidates {
+			if c.v.Major == want.Major && c.v.Minor == want.Minor {
+				filtered = append(filtered, c)
+			}
+		}
+		candidates = filtered
+	}
+
+	best := -1
+	for i, c := range candidates {
+		if best < 0 || c.v.Compare(candidates[best].v) > 0 {
+			best = i
+		}
+	}
+	if best < 0 {
+		return "", errors.New(T("selector.no_match", s.raw))
+	}
+	return candidates[best].name, nil
+}
@@ -0,0 +1,101 @@
+package govm
+
+import "testing"
+
+var publishedVersions = []string{
+	"1", "1.0.1", "1.2", "1.2.2", "1.9beta1", "1.9", "1.16", "1.16.1", "1.16.15",
+	"1.20rc1", "1.20", "1.20.14", "1.21rc2", "1.21rc3", "1.21.0", "1.21.3", "1.21.13",
+	"1.22rc1", "1.22rc2", "1.22.0", "1.22.5", "1.23rc1",
+}
+
+func TestSelectorResolve(t *testing.T) {
+	cases := []struct {
+		selector string
+		want     string
+	}{
+		// exact versions
+		{"1.21.3", "1.21.3"},
+		{"1.16", "1.16"},
+		{"1.16.0", "1.16"},
+		{"1.21rc2", "1.21rc2"},
+		{"1.9beta1", "1.9beta1"},
+		// newest versions
+		{"latest", "1.22.5"},
+		{"stable", "1.22.5"},
+		{"dev-latest", "1.23rc1"},
+		{"oldstable", "1.21.13"},
+		{"latest-1", "1.21.13"},
+		{"latest-2", "1.20.14"},
+		{"latest-0", "1.22.5"},
+		// minor versions
+		{"1.21.x", "1.21.13"},
+		{"1.21x", "1.21.13"},
+		{"1.2x", "1.2.2"},
+		{"1.21@latest", "1.21.13"},
+		{"1.22@dev-latest", "1.22.5"},
+		{"1.23@dev-latest", "1.23rc1"},
+		// ranges
+		{">=1.20 <1.22", "1.21.13"},
+		{">= 1.20, < 1.22", "1.21.13"},
+		{">=1.20,<1.21.3", "1.21.0"},
+		{"<1.21", "1.20.14"},
+		{"<=1.21.3", "1.21.3"},
+		{">1.21.0 !=1.22.5", "1.22.0"},
+		{"=1.16.1", "1.16.1"},
+		{"~1.21", "1.21.13"},
+		{"~1.21.3", "1.21.13"},
+		{"~1.16.2", "1.16.15"},
+		{"^1.21.3", "1.22.5"},
+		{">=1.21rc1 <1.21.0", "1.21rc3"},
+	}
+	for _, c := range cases {
+		sel, err := ParseSelector(c.selector)
+		if err != nil {
+			t.Errorf("ParseSelector(%q): %s", c.selector, err)
+			continue
+		}
+		got, err := sel.Resolve(publishedVersions)
+		if err != nil || got != c.want {
+			t.Errorf("%q resolved to %q, %v, want %q", c.selector, got, err, c.want)
+		}
+	}
+}
+
+func TestSelectorNoMatch(t *testing.T) {
+	for _, s := range []string{"1.19.x", "latest-9", ">=1.30", "~1.17", "1.17.1", ">1.22.5", "1.24@dev-latest"} {
+		sel, err := ParseSelector(s)
+		if err != nil {
+			t.Errorf("ParseSelector(%q): %s", s, err)
+			continue
+		}
+		if got, err := sel.Resolve(publishedVersions); err == nil {
+			t.Errorf("%q resolved to %q, want no match", s, got)
+		}
+	}
+}
+
+func TestParseSelectorInvalid(t *testing.T) {
+	for _, s := range []string{"", "  ", "go1.21", "1.21.x.1", ">=", "~latest", "1x", ">=1.20 <abc", "1.21.0rc1", "latest-x"} {
+		if sel, err := ParseSelector(s); err == nil {
+			t.Errorf("ParseSelector(%q) = %+v, want an error", s, sel)
+		}
+	}
+}
+
+func TestExactSelector(t *testing.T) {
+	for s, want := range map[string]string{"1.21.3": "1.21.3", "1.20.0": "1.20", "1.22rc1": "1.22rc1"} {
+		sel, err := ParseSelector(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := sel.Exact(); !ok || got != want {
+			t.Errorf("%q: Exact() = %q, %v, want %q", s, got, ok, want)
+		}
+	}
+	for _, s := range []string{"latest", "1.21.x", "~1.21"} {
+		sel, _ := ParseSelector(s)
+		if _, ok := sel.Exact(); ok {
+			t.Errorf("%q is exact", s)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package govm
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+const (
+	checksumsFile = "checksums.txt"
+	signatureExt  = ".sig"
+)
+
+// Release channels of govm itself
+const (
+	ChannelStable     = "stable"
+	ChannelPrerelease = "prerelease"
+)
+
+// releasePublicKey is the base64 encoded ed25519 key the checksums of every
+// release are signed with. It is set at release time with
+// -ldflags "-X govm.releasePublicKey=...", builds without it cannot self-update
+var releasePublicKey = ""
+
+// UpgradeOptions for Upgrade
+type UpgradeOptions struct {
+	// Version pins the release to install instead of the latest one
+	Version string
+	// Check only reports whether an update is available
+	Check bool
+	// Channel is ChannelStable or ChannelPrerelease, the configured channel when empty
+	Channel string
+}
+
+// Upgrade replaces the govm binary with a verified release
+func (g *GoVM) Upgrade(currentVersion string, opts UpgradeOptions) error {
+	channel := opts.Channel
+	if channel == "" {
+		channel = g.updateChannel
+	}
+	target := strings.TrimPrefix(opts.Version, "v")
+	if target == "" {
+		latest, err := g.latestRelease(channel)
+		if err != nil {
+			return err
+		}
+		target = latest
+	}
+
+	if opts.Check {
+		if isNewerRelease(target, currentVersion) {
+			InfoT("upgrade.available", target, currentVersion)
+		} else {
+			InfoT("upgrade.newest")
+		}
+		return nil
+	}
+	if opts.Version == "" && !isNewerRelease(target, currentVersion) {
+		InfoT("upgrade.newest")
+		return nil
+	}
+	if target == strings.TrimPrefix(currentVersion, "v") {
+		InfoT("upgrade.already", target)
+		return nil
+	}
+
+	publicKey, err := decodeReleaseKey(releasePublicKey)
+	if err != nil {
+		return err
+	}
+
+	assetName := "govm-" + g.getArch()
+	if runtime.GOOS == "windows" {
+		assetName += ".exe"
+	}
+	baseURL := goVMReleasesUrl + "download/v" + target + "/"
+
+	InfoT("upgrade.verifying", target)
+	checksums, err := fetch(baseURL + checksumsFile)
+	if err != nil {
+		return err
+	}
+	signature, err := fetch(baseURL + checksumsFile + signatureExt)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksums(checksums, signature, publicKey); err != nil {
+		return err
+	}
+	want, err := checksumFor(checksums, assetName)
+	if err != nil {
+		return err
+	}
+
+	binPath := filepath.Join(g.binDir, "govm")
+	if runtime.GOOS == "windows" {
+		binPath += ".exe"
+	}
+	if err := os.MkdirAll(g.binDir, os.ModePerm); err != nil {
+		return err
+	}
+	// download next to the binary so the final rename stays on one file system
+	tmpName := fmt.Sprintf(".govm-%d.new", os.Getpid())
+	tmpFile := filepath.Join(g.binDir, tmpName)
+	defer func() {
+		_ = os.Remove(tmpFile)
+	}()
+	InfoT("upgrade.downloading", target)
+	if err := DownloadWithProgress(baseURL+assetName, tmpName, g.binDir); err != nil {
+		return errors.New(T("upgrade.download_failed", err))
+	}
+	if got, err := fileSHA256(tmpFile); err != nil {
+		return err
+	} else if got != want {
+		return errors.New(T("upgrade.checksum_mismatch", assetName, got, want))
+	}
+	if err := os.Chmod(tmpFile, 0755); err != nil {
+		return errors.New(T("upgrade.chmod_failed", err))
+	}
+
+	if err := swapBinary(tmpFile, binPath); err != nil {
+		return err
+	}
+	SuccessT("upgrade.done", target)
+	return nil
+}
+
+// swapBinary moves newBin over binPath and rolls back to the previous binary
+// when the new one cannot run. The previous binary is kept as a hard link or
+// copy and the new one renamed over it, so there is always a govm binary.
+// Windows cannot replace a running executable, it is renamed away first.
+func swapBinary(newBin, binPath string) error {
+	backup := binPath + ".old"
+	hadBinary := false
+	if info, err := os.Stat(binPath); err == nil {
+		_ = os.Remove(backup)
+		if runtime.GOOS == "windows" {
+			err = os.Rename(binPath, backup)
+		} else if err = os.Link(binPath, backup); err != nil {
+			err = copyFile(binPath, backup, info.Mode().Perm())
+		}
+		traceFS("backup", binPath, err, "target", backup)
+		if err != nil {
+			return err
+		}
+		hadBinary = true
+	}
+
+	err := os.Rename(newBin, binPath)
+	traceFS("rename", newBin, err, "target", binPath)
+	if err == nil {
+		// #nosec G204
+		err = exec.Command(binPath, "version").Run()
+		tracer.Event("exec", "path", binPath, "args", "version", "error", err)
+	}
+	if err != nil {
+		if hadBinary {
+			rollback := os.Rename(backup, binPath)
+			traceFS("rename", backup, rollback, "target", binPath)
+		}
+		return errors.New(T("upgrade.rolled_back", err))
+	}
+
+	if hadBinary {
+		err := os.Remove(backup)
+		traceFS("remove", backup, err)
+	}
+	return nil
+}
+
+// latestRelease returns the version of the latest govm release of channel
+// without the v prefix, the prerelease channel includes stable releases too
+func (g *GoVM) latestRelease(channel string) (string, error) {
+	type release struct {
+		TagName    string `json:"tag_name"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+	}
+
+	switch channel {
+	case ChannelStable, "":
+		data, err := fetch(goVMReleasesApi + "/latest")
+		if err != nil {
+			return "", err
+		}
+		var latest release
+		if err := json.Unmarshal(data, &latest); err != nil || latest.TagName == "" {
+			return "", errors.New(T("upgrade.no_release"))
+		}
+		return strings.TrimPrefix(latest.TagName, "v"), nil
+	case ChannelPrerelease:
+		data, err := fetch(goVMReleasesApi)
+		if err != nil {
+			return "", err
+		}
+		var releases []release
+		if err := json.Unmarshal(data, &releases); err != nil {
+			return "", errors.New(T("upgrade.no_release"))
+		}
+		// releases are listed newest first
+		for _, r := range releases {
+			if !r.Draft && r.TagName != "" {
+				return strings.TrimPrefix(r.TagName, "v"), nil
+			}
+		}
+		return "", errors.New(T("upgrade.no_release"))
+	}
+	return "", errors.New(T("upgrade.bad_channel", channel))
+}
+
+// isNewerRelease compares release versions, versions that are not semantic
+// such as development builds are always older than a release
+func isNewerRelease(release, current string) bool {
+	r, err := semver.NewVersion(release)
+	if err != nil {
+		return false
+	}
+	c, err := semver.NewVersion(current)
+	if err != nil {
+		return true
+	}
+	return r.GreaterThan(c)
+}
+
+func decodeReleaseKey(key string) (ed25519.PublicKey, error) {
+	if key == "" {
+		return nil, errors.New(T("upgrade.no_key"))
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, errors.New(T("upgrade.bad_key"))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// verifyChecksums checks the base64 encoded ed25519 signature of a checksums file
+func verifyChecksums(checksums, signature []byte, publicKey ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || !ed25519.Verify(publicKey, checksums, sig) {
+		return errors.New(T("upgrade.bad_signature"))
+	}
+	return nil
+}
+
+// checksumFor finds the sha256 of name in a "<sha256>  <name>" checksums file
+func checksumFor(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", errors.New(T("upgrade.no_checksum", name))
+}
+
+func fileSHA256(path string) (string, error) {
+	//#nosec G304
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fetch reads a small document such as a checksums file into memory
+func fetch(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "govm")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(T("download.bad_status", resp.Status, url))
+	}
+	return io.ReadAll(resp.Body)
+}
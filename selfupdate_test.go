@@ -0,0 +1,101 @@
+package govm
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestVerifyChecksums(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksums := []byte("0a1b  govm-linux-amd64\n2c3d  govm-darwin-arm64\n")
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, checksums)) + "\n")
+
+	key, err := decodeReleaseKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyChecksums(checksums, sig, key); err != nil {
+		t.Errorf("valid signature rejected: %s", err)
+	}
+	tampered := append([]byte("ffff  govm-linux-amd64\n"), checksums...)
+	if err := verifyChecksums(tampered, sig, key); err == nil {
+		t.Error("tampered checksums accepted")
+	}
+
+	if sum, err := checksumFor(checksums, "govm-darwin-arm64"); err != nil || sum != "2c3d" {
+		t.Errorf("checksumFor = %s, %v", sum, err)
+	}
+	if _, err := checksumFor(checksums, "govm-windows-amd64.exe"); err == nil {
+		t.Error("expected an error for a missing asset")
+	}
+	if _, err := decodeReleaseKey(""); err == nil {
+		t.Error("expected an error for a build without a release key")
+	}
+}
+
+func TestSwapBinaryRollsBack(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as binaries")
+	}
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "govm")
+	newBin := filepath.Join(dir, "govm.new")
+	if err := os.WriteFile(binPath, []byte("#!/bin/sh\necho old\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newBin, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := swapBinary(newBin, binPath); err == nil {
+		t.Fatal("expected the broken binary to be rejected")
+	}
+	if data, _ := os.ReadFile(binPath); string(data) != "#!/bin/sh\necho old\n" {
+		t.Errorf("previous binary not restored: %q", data)
+	}
+
+	if err := os.WriteFile(newBin, []byte("#!/bin/sh\necho new\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(binPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := swapBinary(newBin, binPath); err != nil {
+		t.Fatal(err)
+	}
+	// the new binary was renamed over the old one, which never went missing
+	if after, err := os.Stat(binPath); err != nil || os.SameFile(before, after) {
+		t.Errorf("binary not renamed over: %v", err)
+	}
+	if data, _ := os.ReadFile(binPath); string(data) != "#!/bin/sh\necho new\n" {
+		t.Errorf("binary not replaced: %q", data)
+	}
+	if _, err := os.Stat(binPath + ".old"); !os.IsNotExist(err) {
+		t.Error("backup was not removed")
+	}
+}
+
+func TestIsNewerRelease(t *testing.T) {
+	cases := []struct {
+		release, current string
+		want             bool
+	}{
+		{"0.2.0", "0.1.0", true},
+		{"0.1.0", "0.1.0", false},
+		{"0.1.0", "0.2.0", false},
+		{"0.1.0", "0.0.1.dev", true},
+	}
+	for _, c := range cases {
+		if got := isNewerRelease(c.release, c.current); got != c.want {
+			t.Errorf("isNewerRelease(%s, %s) = %v", c.release, c.current, got)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package govm
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Hidden directories in a versions directory, never listed as versions
+const (
+	stagingPrefix = ".staging-"
+	repairPrefix  = ".repair-"
+	// importKeptPrefix holds a moved tree that could not be put back
+	importKeptPrefix = ".import-kept-"
+)
+
+// staleStagingAge is when a staging dir is removed even if its owner seems alive
+const staleStagingAge = 24 * time.Hour
+
+// stageVersion downloads and extracts version into a new staging dir next
+// to the installed versions, checks that its go command runs and prepares
+// it with finishInstall, keeping the files in skip out of the store. The
+// staging dir is removed on failure, and left behind only when govm is
+// killed, for cleanStaleStaging.
+func (g *GoVM) stageVersion(version, cachedSHA256 string, skip map[string]bool) (string, error) {
+	staging, err := g.newStagingDir()
+	if err != nil {
+		ErrorT("install.staging_failed", err)
+		return "", err
+	}
+	err = g.downloadAndExtract(version, staging, cachedSHA256)
+	if !g.config.Cache.KeepDownloads {
+		g.cleanDownloadsDir()
+	}
+	if err == nil {
+		if err = validateToolchain(version, staging); err != nil {
+			ErrorT("install.validate_failed", version, err)
+		}
+	}
+	if err != nil {
+		removeErr := os.RemoveAll(staging)
+		traceFS("remove", staging, removeErr)
+		return "", err
+	}
+	g.finishInstall(version, staging, skip)
+	return staging, nil
+}
+
+// newStagingDir creates an empty staging dir in the versions directory
+func (g *GoVM) newStagingDir() (string, error) {
+	// the pid lets the next run tell an abandoned staging dir from a running install
+	staging, err := os.MkdirTemp(g.versionsDir, stagingPrefix+strconv.Itoa(os.Getpid())+"-")
+	traceFS("mkdir", staging, err)
+	if err != nil {
+		return "", err
+	}
+	// MkdirTemp creates it 0700, it becomes the version dir other users may run
+	if err := os.Chmod(staging, 0755); err != nil {
+		_ = os.Remove(staging)
+		return "", err
+	}
+	return staging, nil
+}
+
+// commitStaging renames a staged version into place, where existsVersion sees it
+func (g *GoVM) commitStaging(staging, version string) error {
+	dir := g.getVersionDir(version)
+	err := os.Rename(staging, dir)
+	traceFS("rename", staging, err, "target", dir)
+	if err != nil {
+		_ = os.RemoveAll(staging)
+	}
+	return err
+}
+
+// validateToolchain runs go version of the toolchain in dir, which must
+// report version
+func validateToolchain(version, dir string) error {
+	bin := filepath.Join(dir, "go", "bin", goBinary())
+	// #nosec G204
+	cmd := exec.Command(bin, "version")
+	// run outside any module so no go.mod can ask for another toolchain
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOROOT="+filepath.Join(dir, "go"), "GOTOOLCHAIN=local")
+	out, err := cmd.CombinedOutput()
+	tracer.Event("exec", "path", bin, "args", "version", "error", err)
+	if err != nil {
+		return errors.New(strings.TrimSpace(string(out) + " " + err.Error()))
+	}
+	// go version go1.21.3 linux/amd64, go1.21.3 must not pass for 1.21.30
+	fields := strings.Fields(string(out))
+	if len(fields) < 3 || fields[0] != "go" || fields[1] != "version" ||
+		normalizeVersion(strings.TrimPrefix(fields[2], "go")) != normalizeVersion(version) {
+		return errors.New(T("install.version_mismatch", strings.TrimSpace(string(out))))
+	}
+	return nil
+}
+
+// cleanStaleStaging removes the staging dirs of installs that were killed,
+// and finishes repairs killed while swapping the old and the new tree
+func (g *GoVM) cleanStaleStaging() {
+	entries, err := os.ReadDir(g.versionsDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(g.versionsDir, entry.Name())
+		switch {
+		case strings.HasPrefix(entry.Name(), stagingPrefix):
+			if !stagingAbandoned(entry.Name(), path) {
+				continue
+			}
+			DebugT("install.stale_staging", path)
+			err := os.RemoveAll(path)
+			traceFS("remove", path, err)
+		case strings.HasPrefix(entry.Name(), repairPrefix):
+			// a repair holds the lock on its version until the swap is done
+			version := strings.TrimPrefix(entry.Name(), repairPrefix)
+			unlock, err := g.lockVersion(version)
+			if err != nil {
+				continue
+			}
+			if _, err := os.Stat(g.getVersionDir(version)); err == nil {
+				err := os.RemoveAll(path)
+				traceFS("remove", path, err)
+			} else {
+				// killed between the two renames, put the old tree back
+				err := os.Rename(path, g.getVersionDir(version))
+				traceFS("rename", path, err, "target", g.getVersionDir(version))
+			}
+			unlock()
+		}
+	}
+}
+
+// stagingAbandoned tells whether the install owning a staging dir is gone
+func stagingAbandoned(name, path string) bool {
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleStagingAge {
+		return true
+	}
+	pid, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(name, stagingPrefix), "-", 2)[0])
+	if err != nil {
+		return true
+	}
+	return pid != os.Getpid() && !processAlive(pid)
+}
+
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess fails on Windows when the process is gone
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,95 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestValidateToolchain(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go command is a shell script")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "go", "bin")
+	if err := os.MkdirAll(bin, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "go"), []byte("#!/bin/sh\necho go version go1.17.6 linux/amd64\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateToolchain("1.17.6", dir); err != nil {
+		t.Error(err)
+	}
+	for _, version := range []string{"1.17.7", "1.17", "1.17.60"} {
+		if err := validateToolchain(version, dir); err == nil {
+			t.Errorf("accepted go1.17.6 for %s", version)
+		}
+	}
+	if err := validateToolchain("1.17.6", t.TempDir()); err == nil {
+		t.Error("accepted a missing go command")
+	}
+}
+
+func TestNewStagingDir(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.mkdirs()
+	staging, err := g.newStagingDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(staging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+		t.Errorf("staging dir mode = %v", info.Mode())
+	}
+	if stagingAbandoned(filepath.Base(staging), staging) {
+		t.Error("own staging dir is abandoned")
+	}
+}
+
+func TestCleanStaleStaging(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a pid that cannot be running, and this process
+	abandoned := filepath.Join(g.versionsDir, stagingPrefix+"999999999-1")
+	running := filepath.Join(g.versionsDir, stagingPrefix+strconv.Itoa(os.Getpid())+"-1")
+	interrupted := filepath.Join(g.versionsDir, repairPrefix+"1.17.6")
+	finished := filepath.Join(g.versionsDir, repairPrefix+"1.16")
+	repairing := filepath.Join(g.versionsDir, repairPrefix+"1.15")
+	for _, dir := range []string{abandoned, running, filepath.Join(interrupted, "go"), finished, filepath.Join(g.versionsDir, "1.16", "go"), repairing} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the repair of 1.15 is still running in this process
+	lock := filepath.Join(g.versionsDir, lockPrefix+"1.15")
+	if err := os.WriteFile(lock, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g.cleanStaleStaging()
+	for dir, want := range map[string]bool{abandoned: false, running: true, interrupted: false, finished: false, repairing: true, lock: true} {
+		if _, err := os.Stat(dir); (err == nil) != want {
+			t.Errorf("%s exists: %v, want %v", dir, err == nil, want)
+		}
+	}
+	if _, _, ok := g.findVersion("1.17.6"); !ok {
+		t.Error("interrupted repair not restored")
+	}
+	if versions, _ := g.InstalledVersions(false); len(versions) != 2 {
+		t.Errorf("staging dirs listed: %+v", versions)
+	}
+}
@@ -0,0 +1,245 @@
+package govm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StoreStats describes the content-addressed store of a versions directory
+type StoreStats struct {
+	Dir string `json:"dir"`
+	// Versions is the number of versions linked from the store
+	Versions int `json:"versions"`
+	Blobs    int `json:"blobs"`
+	// StoredBytes is the size of the blobs
+	StoredBytes int64 `json:"stored_bytes"`
+	// LinkedBytes is the size the linked versions would take without the store
+	LinkedBytes int64 `json:"linked_bytes"`
+	SavedBytes  int64 `json:"saved_bytes"`
+	// Unreferenced blobs are removed by the next garbage collection
+	Unreferenced int `json:"unreferenced"`
+}
+
+// blobsDir is the content-addressed store next to versionsDir,
+// hard links cannot cross file systems so every versions directory has its own
+func blobsDir(versionsDir string) string {
+	return filepath.Join(filepath.Dir(versionsDir), "store", "blobs")
+}
+
+// blobName keys a file by its content and mode, files sharing a blob share their mode
+func blobName(f ManifestFile) string {
+	return filepath.Join(f.SHA256[:2], fmt.Sprintf("%s-%o", f.SHA256, f.Mode))
+}
+
+// dedupe replaces every file of the manifest m of the version in dir by a
+// hard link to its blob, files without a blob become the blob. Blobs are
+// read-only so an edit cannot reach the other versions, m gets their modes.
+// A blob that no longer matches its name was edited anyway and is replaced
+// by the file. The files in skip keep their own copy.
+func (g *GoVM) dedupe(dir string, m *Manifest, skip map[string]bool) error {
+	blobs := blobsDir(g.versionsDir)
+	for i := range m.Files {
+		f := &m.Files[i]
+		if skip[f.Path] {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		mode := f.Mode &^ 0222
+		stored := *f
+		stored.Mode = mode
+		blob := filepath.Join(blobs, blobName(stored))
+		if intactBlob(blob, stored) {
+			if err := linkBlob(blob, path); err != nil {
+				return err
+			}
+			f.Mode = mode
+			continue
+		}
+		if err := os.Remove(blob); err == nil {
+			tracer.Event("evict", "blob", blob)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(blob), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+		f.Mode = mode
+		if err := os.Link(path, blob); err != nil {
+			return err
+		}
+	}
+	tracer.Event("dedupe", "version", m.Version, "files", len(m.Files))
+	return nil
+}
+
+// relinkVersions links the files of every version to the blobs of the store
+// again, after a copy of the versions and the store made them separate files.
+// Files without a blob keep their own copy, failures are reported.
+func (g *GoVM) relinkVersions() {
+	entries, err := os.ReadDir(g.versionsDir)
+	if err != nil {
+		return
+	}
+	blobs := blobsDir(g.versionsDir)
+	for _, entry := range entries {
+		dir := filepath.Join(g.versionsDir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".") || linkedTree(dir) {
+			continue
+		}
+		m, err := readManifest(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range m.Files {
+			blob := filepath.Join(blobs, blobName(f))
+			if !intactBlob(blob, f) {
+				continue
+			}
+			if err := linkBlob(blob, filepath.Join(dir, filepath.FromSlash(f.Path))); err != nil {
+				ErrorT("store.dedupe_failed", entry.Name(), err)
+				break
+			}
+		}
+	}
+}
+
+// linkBlob replaces the file at path by a hard link to blob, it links next to
+// the file and renames over it, so the file is never missing
+func linkBlob(blob, path string) error {
+	tmp := path + ".govm-link"
+	if err := os.Link(blob, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// intactBlob tells whether blob exists with the content and mode of f it is named after
+func intactBlob(blob string, f ManifestFile) bool {
+	info, err := os.Stat(blob)
+	if err != nil || info.Size() != f.Size || info.Mode().Perm() != f.Mode {
+		return false
+	}
+	sum, err := fileSHA256(blob)
+	return err == nil && sum == f.SHA256
+}
+
+// referencedBlobs collects the blobs named by the manifests in versionsDir
+// and the number of versions that have one
+func referencedBlobs(versionsDir string) (map[string]int64, int, error) {
+	refs := make(map[string]int64)
+	entries, err := os.ReadDir(versionsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, 0, err
+	}
+	versions := 0
+	for _, entry := range entries {
+		m, err := readManifest(filepath.Join(versionsDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		// staged installs keep their blobs but are no versions yet
+		if !strings.HasPrefix(entry.Name(), ".") {
+			versions++
+		}
+		for _, f := range m.Files {
+			refs[blobName(f)] += f.Size
+		}
+	}
+	return refs, versions, nil
+}
+
+// walkBlobs calls fn with the name and size of every blob of the store
+func walkBlobs(dir string, fn func(name string, size int64) error) error {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return fn(name, info.Size())
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// StoreStats reports the space the content-addressed store saves
+func (g *GoVM) StoreStats() (StoreStats, error) {
+	stats := StoreStats{Dir: blobsDir(g.versionsDir)}
+	refs, versions, err := referencedBlobs(g.versionsDir)
+	if err != nil {
+		return stats, err
+	}
+	stats.Versions = versions
+
+	var referenced int64
+	err = walkBlobs(stats.Dir, func(name string, size int64) error {
+		stats.Blobs++
+		stats.StoredBytes += size
+		if linked, ok := refs[name]; ok {
+			referenced += size
+			stats.LinkedBytes += linked
+		} else {
+			stats.Unreferenced++
+		}
+		return nil
+	})
+	stats.SavedBytes = stats.LinkedBytes - referenced
+	return stats, err
+}
+
+// CollectGarbage removes the blobs no installed version links to anymore
+// and returns how many were removed and their size. Removing a blob never
+// breaks a version, which keeps its own hard link to the content.
+func (g *GoVM) CollectGarbage() (int, int64, error) {
+	dir := blobsDir(g.versionsDir)
+	refs, _, err := referencedBlobs(g.versionsDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	removed, freed := 0, int64(0)
+	err = walkBlobs(dir, func(name string, size int64) error {
+		if _, ok := refs[name]; ok {
+			return nil
+		}
+		path := filepath.Join(dir, name)
+		err := os.Remove(path)
+		traceFS("remove", path, err)
+		if err != nil {
+			return err
+		}
+		removed++
+		freed += size
+		return nil
+	})
+	// drop the emptied fan-out directories
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				_ = os.Remove(filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return removed, freed, err
+}
@@ -0,0 +1,170 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	files := map[string]map[string]string{
+		"1.17.6": {"go/VERSION": "go1.17.6", "go/src/fmt/print.go": "package fmt"},
+		"1.17.7": {"go/VERSION": "go1.17.7", "go/src/fmt/print.go": "package fmt"},
+	}
+	for version, tree := range files {
+		for name, content := range tree {
+			path := filepath.Join(home, "versions", version, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []string{"1.17.6", "1.17.7"} {
+		g.config.Store.Dedupe = true
+		g.finishInstall(version, g.getVersionDir(version), nil)
+	}
+
+	a, err := os.Stat(filepath.Join(home, "versions", "1.17.6", "go", "src", "fmt", "print.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.Stat(filepath.Join(home, "versions", "1.17.7", "go", "src", "fmt", "print.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(a, b) {
+		t.Error("identical files are not linked")
+	}
+	if m, err := readManifest(g.getVersionDir("1.17.6")); err != nil || len(m.Files) != 2 {
+		t.Errorf("manifest %+v, %v", m, err)
+	}
+
+	stats, err := g.StoreStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Versions != 2 || stats.Blobs != 3 || stats.SavedBytes != int64(len("package fmt")) || stats.Unreferenced != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	g.cleanVersionDir("1.17.7")
+	removed, freed, err := g.CollectGarbage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || freed != int64(len("go1.17.7")) {
+		t.Errorf("gc removed %d blobs, %d bytes", removed, freed)
+	}
+	if data, err := os.ReadFile(filepath.Join(home, "versions", "1.17.6", "go", "VERSION")); err != nil || string(data) != "go1.17.6" {
+		t.Errorf("1.17.6 broken after gc: %q, %v", data, err)
+	}
+}
+
+func TestDedupeRepair(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	writeTree := func(dir, content string) {
+		path := filepath.Join(dir, "go", "src", "fmt", "print.go")
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, version := range []string{"1.17.6", "1.17.7"} {
+		writeTree(filepath.Join(home, "versions", version), "package fmt")
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.config.Store.Dedupe = true
+	for _, version := range []string{"1.17.6", "1.17.7"} {
+		g.finishInstall(version, g.getVersionDir(version), nil)
+	}
+	edited := filepath.Join(home, "versions", "1.17.7", "go", "src", "fmt", "print.go")
+	info, err := os.Stat(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0222 != 0 {
+		t.Errorf("deduped file mode %v is writable", info.Mode())
+	}
+	if r, err := g.Verify("1.17.7"); err != nil || !r.OK() {
+		t.Fatalf("deduped install: %+v, %v", r, err)
+	}
+
+	// an edit in place reaches every version sharing the blob
+	if err := os.Chmod(edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(edited, []byte("package evil"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	damaged := g.damagedFiles("1.17.7")
+	if !damaged["go/src/fmt/print.go"] {
+		t.Fatalf("damaged = %v", damaged)
+	}
+
+	// repair stages a clean tree, the damaged file keeps its own copy
+	staging := filepath.Join(t.TempDir(), "1.17.7")
+	writeTree(staging, "package fmt")
+	g.finishInstall("1.17.7", staging, damaged)
+	repaired := filepath.Join(staging, "go", "src", "fmt", "print.go")
+	if data, err := os.ReadFile(repaired); err != nil || string(data) != "package fmt" {
+		t.Errorf("repaired file = %q, %v", data, err)
+	}
+	a, err := os.Stat(repaired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.SameFile(a, info) {
+		t.Error("repaired file is linked to the edited blob")
+	}
+
+	// a later install drops the edited blob for its clean file
+	other := filepath.Join(t.TempDir(), "1.17.8")
+	writeTree(other, "package fmt")
+	g.finishInstall("1.17.8", other, nil)
+	b, err := os.Stat(filepath.Join(other, "go", "src", "fmt", "print.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.SameFile(b, info) {
+		t.Error("clean file is linked to the edited blob")
+	}
+	m, err := readManifest(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := filepath.Join(blobsDir(g.versionsDir), blobName(m.Files[0]))
+	if data, err := os.ReadFile(blob); err != nil || string(data) != "package fmt" {
+		t.Errorf("blob = %q, %v", data, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:           "0 B",
+		1023:        "1023 B",
+		1536:        "1.5 KiB",
+		1 << 20:     "1.0 MiB",
+		5 << 30:     "5.0 GiB",
+		3 << 40 / 2: "1.5 TiB",
+	} {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package govm
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Stores a version can be installed in
+const (
+	StoreUser   = "user"
+	StoreSystem = "system"
+)
+
+// versionStore is a versions directory searched for installed versions
+type versionStore struct {
+	name string
+	dir  string
+}
+
+func defaultSystemStore() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			return filepath.Join(dir, "govm")
+		}
+	}
+	return "/opt/govm"
+}
+
+// stores lists the versions directories in lookup order: the user store
+// first, then the read-only system store shared by every user.
+// With --system only the system store is used.
+func (g *GoVM) stores() []versionStore {
+	if g.system {
+		return []versionStore{{StoreSystem, g.systemVersionsDir}}
+	}
+	return []versionStore{{StoreUser, g.versionsDir}, {StoreSystem, g.systemVersionsDir}}
+}
+
+// findVersion returns the directory and store of the first installation of version
+func (g *GoVM) findVersion(version string) (string, string, bool) {
+	for _, s := range g.stores() {
+		dir := filepath.Join(s.dir, version)
+		if info, err := os.Stat(filepath.Join(dir, "go")); err == nil && info.IsDir() {
+			return dir, s.name, true
+		}
+	}
+	return "", "", false
+}
+
+// CurrentVersionPath is the directory of the active version in the store
+// holding it, empty without an active version
+func (g *GoVM) CurrentVersionPath() string {
+	current := g.currentVersionDir()
+	if current == "" {
+		return ""
+	}
+	for _, dir := range []string{g.versionsDir, g.systemVersionsDir} {
+		path := filepath.Join(dir, filepath.Base(current))
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved == current {
+			return path
+		}
+	}
+	return ""
+}
+
+// checkSystemStore makes sure the system store can be written, which
+// usually takes root or administrator rights
+func (g *GoVM) checkSystemStore() error {
+	if err := os.MkdirAll(g.systemVersionsDir, 0755); err != nil {
+		return errors.New(T("system.not_writable", g.systemVersionsDir))
+	}
+	f, err := os.CreateTemp(g.systemVersionsDir, ".govm-write-check-")
+	if err != nil {
+		return errors.New(T("system.not_writable", g.systemVersionsDir))
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return nil
+}
+
+// makeReadable lets every user read dir and run its executables
+func makeReadable(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mode := info.Mode().Perm() | 0444
+		if d.IsDir() || mode&0111 != 0 {
+			mode |= 0111
+		}
+		if mode == info.Mode().Perm() {
+			return nil
+		}
+		return os.Chmod(path, mode)
+	})
+}
@@ -0,0 +1,102 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSystemStore(t *testing.T) {
+	home, store := t.TempDir(), t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", store)
+	for _, dir := range []string{
+		filepath.Join(home, "versions", "1.17.6", "go", "bin"),
+		filepath.Join(store, "versions", "1.17.6", "go", "bin"),
+		filepath.Join(store, "versions", "1.18", "go", "bin"),
+	} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dir, s, _ := g.findVersion("1.17.6"); s != StoreUser || dir != filepath.Join(home, "versions", "1.17.6") {
+		t.Errorf("1.17.6 found in %s %s, want the user store first", s, dir)
+	}
+	if _, s, _ := g.findVersion("1.18"); s != StoreSystem {
+		t.Errorf("1.18 found in %q", s)
+	}
+
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.18")
+	if g.CurrentVersion() != "1.18" {
+		t.Errorf("current = %q", g.CurrentVersion())
+	}
+	if path := g.CurrentVersionPath(); path != filepath.Join(store, "versions", "1.18") {
+		t.Errorf("current path = %q, want the system store", path)
+	}
+
+	versions, err := g.InstalledVersions(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		version, store string
+		active         bool
+	}{
+		{"1.17.6", StoreUser, false},
+		{"1.17.6", StoreSystem, false},
+		{"1.18", StoreSystem, true},
+	}
+	if len(versions) != len(want) {
+		t.Fatalf("got %+v", versions)
+	}
+	for i, w := range want {
+		if v := versions[i]; v.Version != w.version || v.Store != w.store || v.Active != w.active {
+			t.Errorf("versions[%d] = %+v, want %+v", i, v, w)
+		}
+	}
+
+	sys, err := NewGoVmWithOptions(Options{Home: home, System: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions, _ := sys.InstalledVersions(false); len(versions) != 2 {
+		t.Errorf("--system lists %+v", versions)
+	}
+	if err := sys.checkSystemStore(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMakeReadable(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "go", "bin")
+	if err := os.MkdirAll(bin, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "go"), nil, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go", "VERSION"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := makeReadable(dir); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]os.FileMode{
+		bin:                                 0755,
+		filepath.Join(bin, "go"):            0755,
+		filepath.Join(dir, "go", "VERSION"): 0644,
+	} {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != want {
+			t.Errorf("%s: %v, want %v", path, info.Mode().Perm(), want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package govm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trace formats
+const (
+	TraceText = "text"
+	TraceJSON = "json"
+)
+
+// TraceOptions select where and how the debug trace is written
+type TraceOptions struct {
+	// Enabled writes the trace to stderr
+	Enabled bool
+	// Format is TraceText or TraceJSON
+	Format string
+	// File writes the trace to a new file in Dir as well
+	File bool
+	Dir  string
+}
+
+// Tracer writes one structured record per HTTP request and file operation
+type Tracer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format string
+}
+
+var tracer = &Tracer{}
+
+// httpClient is used for every request govm makes so they all show up in the trace
+var httpClient = newHTTPClient("")
+
+// newHTTPClient that sends requests through proxy, or the proxy of the environment when empty
+func newHTTPClient(proxy string) *http.Client {
+	var base http.RoundTripper = http.DefaultTransport
+	if proxy != "" {
+		if u, err := url.Parse(proxy); err == nil {
+			t := http.DefaultTransport.(*http.Transport).Clone()
+			t.Proxy = http.ProxyURL(u)
+			base = t
+		}
+	}
+	return &http.Client{Transport: tracingTransport{base}}
+}
+
+// NewTracer writing records in format to w, a nil w disables tracing
+func NewTracer(w io.Writer, format string) *Tracer {
+	return &Tracer{w: w, format: format}
+}
+
+// SetTracer replaces the tracer used by govm
+func SetTracer(t *Tracer) {
+	tracer = t
+}
+
+// CurrentTracer returns the tracer used by govm
+func CurrentTracer() *Tracer {
+	return tracer
+}
+
+// ParseTraceEnv applies GOVM_LOG, a comma separated list of
+// debug (trace to stderr), text or json (format) and file (trace to the logs dir)
+func ParseTraceEnv(value string, opts *TraceOptions) error {
+	for _, token := range strings.Split(value, ",") {
+		switch strings.TrimSpace(strings.ToLower(token)) {
+		case "":
+		case "debug", "1", "true":
+			opts.Enabled = true
+		case TraceText, TraceJSON:
+			opts.Format = strings.TrimSpace(strings.ToLower(token))
+		case "file":
+			opts.File = true
+		default:
+			return errors.New(T("trace.unknown_option", token))
+		}
+	}
+	return nil
+}
+
+// StartTrace installs a tracer for opts and returns the path of the trace file,
+// if any, and a function that closes it
+func StartTrace(opts TraceOptions, stderr io.Writer) (string, func() error, error) {
+	noop := func() error { return nil }
+	if opts.Format == "" {
+		opts.Format = TraceText
+	}
+	if opts.Format != TraceText && opts.Format != TraceJSON {
+		return "", noop, errors.New(T("trace.unknown_format", opts.Format))
+	}
+
+	var writers []io.Writer
+	if opts.Enabled {
+		writers = append(writers, stderr)
+	}
+	path := ""
+	closeFile := noop
+	if opts.File {
+		if err := os.MkdirAll(opts.Dir, os.ModePerm); err != nil {
+			return "", noop, err
+		}
+		path = filepath.Join(opts.Dir, "govm-"+time.Now().Format("20060102-150405")+".log")
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return "", noop, err
+		}
+		writers = append(writers, f)
+		closeFile = f.Close
+	}
+
+	if len(writers) == 0 {
+		SetTracer(&Tracer{})
+		return "", noop, nil
+	}
+	SetTracer(NewTracer(io.MultiWriter(writers...), opts.Format))
+	return path, closeFile, nil
+}
+
+// Enabled reports whether records are written at all
+func (t *Tracer) Enabled() bool {
+	return t.w != nil
+}
+
+// Event writes a record named msg with alternating key and value pairs
+func (t *Tracer) Event(msg string, keyvals ...interface{}) {
+	if !t.Enabled() {
+		return
+	}
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.format == TraceJSON {
+		record := map[string]interface{}{"time": now.Format(time.RFC3339Nano), "msg": msg}
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			record[fmt.Sprint(keyvals[i])] = traceValue(keyvals[i+1])
+		}
+		data, _ := json.Marshal(record)
+		_, _ = fmt.Fprintf(t.w, "%s\n", data)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("time=" + now.Format(time.RFC3339Nano) + " msg=" + quoteTraceValue(msg))
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		b.WriteString(fmt.Sprintf(" %v=%s", keyvals[i], quoteTraceValue(fmt.Sprint(traceValue(keyvals[i+1])))))
+	}
+	b.WriteString("\n")
+	_, _ = io.WriteString(t.w, b.String())
+}
+
+func traceValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	}
+	return v
+}
+
+func quoteTraceValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// traceFS records a file operation and its outcome
+func traceFS(op string, path string, err error, keyvals ...interface{}) {
+	keyvals = append([]interface{}{"op", op, "path", path}, keyvals...)
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+	}
+	tracer.Event("fs", keyvals...)
+}
+
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	keyvals := []interface{}{"method", req.Method, "url", req.URL.String(), "mirror", req.URL.Host, "duration", time.Since(start)}
+	if err != nil {
+		tracer.Event("http", append(keyvals, "error", err)...)
+		return resp, err
+	}
+	tracer.Event("http", append(keyvals, "status", resp.StatusCode, "content_length", resp.ContentLength)...)
+	return resp, nil
+}
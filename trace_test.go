@@ -0,0 +1,43 @@
+package govm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTracerFormats(t *testing.T) {
+	var text bytes.Buffer
+	NewTracer(&text, TraceText).Event("fs", "op", "mkdir", "path", "/tmp/my dir", "error", errors.New("denied"))
+	line := text.String()
+	for _, want := range []string{"msg=fs", "op=mkdir", `path="/tmp/my dir"`, "error=denied"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("text record %q is missing %q", line, want)
+		}
+	}
+
+	var js bytes.Buffer
+	NewTracer(&js, TraceJSON).Event("http", "status", 404, "url", "https://golang.org/dl/")
+	var record map[string]interface{}
+	if err := json.Unmarshal(js.Bytes(), &record); err != nil {
+		t.Fatalf("invalid json record %q: %s", js.String(), err)
+	}
+	if record["msg"] != "http" || record["status"] != float64(404) {
+		t.Errorf("unexpected json record %v", record)
+	}
+}
+
+func TestParseTraceEnv(t *testing.T) {
+	var opts TraceOptions
+	if err := ParseTraceEnv("debug, json,file", &opts); err != nil {
+		t.Fatal(err)
+	}
+	if !opts.Enabled || !opts.File || opts.Format != TraceJSON {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if err := ParseTraceEnv("verbose", &opts); err == nil {
+		t.Error("expected an error for an unknown option")
+	}
+}
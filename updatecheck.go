@@ -0,0 +1,112 @@
+package govm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+const (
+	updateCheckFile     = "update-check.json"
+	updateCheckInterval = 24 * time.Hour
+)
+
+// UpdateCheck is the cached result of the daily check for newer releases
+type UpdateCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Channel   string    `json:"channel"`
+	// GoVM is the latest govm release of Channel
+	GoVM string `json:"govm"`
+	// Go maps every minor version to its latest stable patch release
+	Go map[string]string `json:"go"`
+}
+
+func (g *GoVM) updateCheckPath() string {
+	return filepath.Join(g.cacheDir, updateCheckFile)
+}
+
+// UpdateCheckEnabled reports whether update notifications are turned on
+func (g *GoVM) UpdateCheckEnabled() bool {
+	return g.updateCheck
+}
+
+// ReadUpdateCheck returns the cached update check, it never touches the network
+func (g *GoVM) ReadUpdateCheck() (UpdateCheck, error) {
+	var c UpdateCheck
+	data, err := os.ReadFile(g.updateCheckPath())
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+func (g *GoVM) writeUpdateCheck(c UpdateCheck) error {
+	if err := os.MkdirAll(g.cacheDir, os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(g.updateCheckPath(), data, 0644)
+	traceFS("write", g.updateCheckPath(), err)
+	return err
+}
+
+// UpdateCheckDue reports whether the cached check is older than a day or was
+// made for another channel. A due check is marked as started right away so
+// concurrent commands do not all start one.
+func (g *GoVM) UpdateCheckDue() bool {
+	c, err := g.ReadUpdateCheck()
+	if err == nil && c.Channel == g.updateChannel && time.Since(c.CheckedAt) < updateCheckInterval {
+		return false
+	}
+	c.CheckedAt = time.Now()
+	c.Channel = g.updateChannel
+	return g.writeUpdateCheck(c) == nil
+}
+
+// RefreshUpdateCheck fetches the latest govm and Go releases into the cache
+func (g *GoVM) RefreshUpdateCheck() error {
+	latest, err := g.latestRelease(g.updateChannel)
+	if err != nil {
+		return err
+	}
+	c := UpdateCheck{
+		CheckedAt: time.Now(),
+		Channel:   g.updateChannel,
+		GoVM:      latest,
+		Go:        make(map[string]string),
+	}
+	for _, group := range groupVersions(g.remoteVersionNames()) {
+		for _, v := range group.Versions {
+			if v.Stability == StabilityStable {
+				c.Go[group.Minor] = v.Version
+			}
+		}
+	}
+	return g.writeUpdateCheck(c)
+}
+
+// UpdateNotices lists the newer govm and Go patch releases known from the cache
+func (g *GoVM) UpdateNotices(currentGoVM string) []string {
+	c, err := g.ReadUpdateCheck()
+	if err != nil {
+		return nil
+	}
+	var notices []string
+	// development builds are not nagged about releases
+	if _, err := semver.NewVersion(currentGoVM); err == nil && c.GoVM != "" && isNewerRelease(c.GoVM, currentGoVM) {
+		notices = append(notices, T("notice.govm", c.GoVM, c.Channel))
+	}
+	if current, err := ParseVersion(g.CurrentVersion()); err == nil && !current.Prerelease() {
+		if latest, err := ParseVersion(c.Go[current.MinorVersion()]); err == nil && latest.Compare(current) > 0 {
+			notices = append(notices, T("notice.go", latest, current, latest))
+		}
+	}
+	return notices
+}
@@ -0,0 +1,73 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpdateCheckDue(t *testing.T) {
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.UpdateCheckDue() {
+		t.Fatal("a missing cache should be due")
+	}
+	if g.UpdateCheckDue() {
+		t.Fatal("a started check should not be due again")
+	}
+	g.updateChannel = ChannelPrerelease
+	if !g.UpdateCheckDue() {
+		t.Fatal("a check of another channel should be due")
+	}
+	if err := g.writeUpdateCheck(UpdateCheck{CheckedAt: time.Now().Add(-25 * time.Hour), Channel: ChannelPrerelease}); err != nil {
+		t.Fatal(err)
+	}
+	if !g.UpdateCheckDue() {
+		t.Fatal("a day old check should be due")
+	}
+}
+
+func TestUpdateNotices(t *testing.T) {
+	SetLocale(LocaleEN)
+	home := t.TempDir()
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notices := g.UpdateNotices("1.0.0"); len(notices) != 0 {
+		t.Fatalf("notices without a cache: %v", notices)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, "versions", "1.16.2", "go", "bin"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.16.2")
+	if err := g.writeUpdateCheck(UpdateCheck{
+		CheckedAt: time.Now(),
+		Channel:   ChannelStable,
+		GoVM:      "1.1.0",
+		Go:        map[string]string{"1.16": "1.16.15", "1.17": "1.17.8"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	notices := g.UpdateNotices("1.0.0")
+	if len(notices) != 2 {
+		t.Fatalf("got %q", notices)
+	}
+	if want := T("notice.go", "1.16.15", "1.16.2", "1.16.15"); notices[1] != want {
+		t.Errorf("got %q, want %q", notices[1], want)
+	}
+	if notices := g.UpdateNotices("1.1.0"); len(notices) != 1 {
+		t.Errorf("up to date govm still notified: %q", notices)
+	}
+	if notices := g.UpdateNotices("0.0.1.dev"); len(notices) != 1 {
+		t.Errorf("development build notified: %q", notices)
+	}
+}
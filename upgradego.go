@@ -0,0 +1,148 @@
+package govm
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// GoUpgradeOptions for PlanGoUpgrade
+type GoUpgradeOptions struct {
+	// CurrentOnly upgrades the minor version of the active version only
+	CurrentOnly bool
+	// Prune removes the patches superseded by the upgrade
+	Prune bool
+}
+
+// GoUpgrade moves one minor version to its latest patch
+type GoUpgrade struct {
+	Minor string `json:"minor"`
+	// From is the newest installed patch and To the latest published one
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Superseded are the installed patches older than To
+	Superseded []string `json:"superseded"`
+	// Current tells that the active version moves to To
+	Current bool `json:"current"`
+	// Aliases are the aliases moving to To
+	Aliases []string `json:"aliases,omitempty"`
+	// Prune lists the superseded patches removed afterwards
+	Prune []string `json:"prune,omitempty"`
+}
+
+// PlanGoUpgrade finds the installed minor versions with a newer stable patch
+// in the published versions
+func (g *GoVM) PlanGoUpgrade(opts GoUpgradeOptions) ([]GoUpgrade, error) {
+	installed, err := g.InstalledVersions(false)
+	if err != nil {
+		return nil, err
+	}
+	current, _ := ParseVersion(g.CurrentVersion())
+	own := StoreUser
+	if g.system {
+		own = StoreSystem
+	}
+
+	lines := make(map[string][]InstalledVersion)
+	var minors []string
+	for _, v := range installed {
+		gv, err := ParseVersion(v.Version)
+		if err != nil || gv.Prerelease() {
+			continue
+		}
+		if opts.CurrentOnly && (g.CurrentVersion() == "" || gv.MinorVersion() != current.MinorVersion()) {
+			continue
+		}
+		minor := gv.MinorVersion()
+		if _, ok := lines[minor]; !ok {
+			minors = append(minors, minor)
+		}
+		lines[minor] = append(lines[minor], v)
+	}
+	if len(minors) == 0 {
+		return nil, nil
+	}
+
+	latest := make(map[string]string)
+	InfoT("remote.fetching")
+	for _, group := range groupVersions(g.remoteVersionNames()) {
+		for _, v := range group.Versions {
+			if v.Stability == StabilityStable {
+				latest[group.Minor] = v.Version
+			}
+		}
+	}
+
+	aliases := g.aliasesByVersion()
+	// an exact pin keeps its version, a pin of a minor version moves to the new patch
+	pins := make(map[string][]string)
+	if opts.Prune {
+		if pins, err = findPins(g.config.Projects.Dirs); err != nil {
+			return nil, err
+		}
+	}
+	var plan []GoUpgrade
+	for _, minor := range minors {
+		to, ok := latest[minor]
+		if !ok {
+			continue
+		}
+		target, _ := ParseVersion(to)
+		// installed versions are in release order, the last one is the newest
+		line := lines[minor]
+		u := GoUpgrade{Minor: minor, From: line[len(line)-1].Version, To: to}
+		for _, v := range line {
+			if gv, _ := ParseVersion(v.Version); gv.Compare(target) >= 0 {
+				continue
+			}
+			u.Superseded = append(u.Superseded, v.Version)
+			if v.Active {
+				u.Current = true
+			}
+			u.Aliases = append(u.Aliases, aliases[v.Version]...)
+			if opts.Prune && v.Store == own && len(pins[v.Version]) == 0 {
+				u.Prune = append(u.Prune, v.Version)
+			}
+		}
+		if len(u.Superseded) == 0 {
+			continue
+		}
+		sort.Strings(u.Aliases)
+		plan = append(plan, u)
+	}
+	return plan, nil
+}
+
+// UpgradeGo installs the latest patches of the plan, moves the active
+// version and the aliases to them and removes the pruned patches through
+// PlanPrune, which keeps what is still current, pinned or aliased. It stops
+// at the first version that cannot be installed.
+func (g *GoVM) UpgradeGo(plan []GoUpgrade) error {
+	var superseded []string
+	for _, u := range plan {
+		if err := g.install(u.To); err != nil {
+			return errors.New(T("upgrade_go.install_failed", u.To, err))
+		}
+		if u.Current {
+			g.Use(u.To)
+		}
+		for _, from := range u.Superseded {
+			moved, err := g.moveAliases(from, u.To)
+			if err != nil {
+				return err
+			}
+			for _, a := range moved {
+				SuccessT("alias.updated", a.Name, a.Version, a.Selector)
+			}
+		}
+		superseded = append(superseded, u.Prune...)
+	}
+	if len(superseded) == 0 {
+		return nil
+	}
+	decisions, err := g.PlanPrune(PrunePolicy{KeepPinned: g.config.Projects.Dirs, Superseded: superseded}, time.Now())
+	if err != nil {
+		return err
+	}
+	return g.Prune(decisions)
+}
@@ -0,0 +1,143 @@
+package govm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanGoUpgrade(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	home := t.TempDir()
+	for _, v := range []string{"1.20.1", "1.21.4", "1.21.5", "1.22rc1"} {
+		if err := os.MkdirAll(filepath.Join(home, "versions", v, "go", "bin"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.20.1")
+	defer func(tags map[string][]string) { githubTags = tags }(githubTags)
+	githubTags = map[string][]string{"golang/go": {"go1.20.1", "go1.21.4", "go1.21.5", "go1.21.6", "go1.22rc1", "go1.22.0"}}
+	if _, err := g.SetAlias("prod", "1.21.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := g.PlanGoUpgrade(GoUpgradeOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GoUpgrade{{
+		Minor: "1.21", From: "1.21.5", To: "1.21.6",
+		Superseded: []string{"1.21.4", "1.21.5"},
+		Aliases:    []string{"prod"},
+		Prune:      []string{"1.21.4", "1.21.5"},
+	}}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("plan = %+v, want %+v", plan, want)
+	}
+
+	// the current version is on its latest patch
+	if plan, err := g.PlanGoUpgrade(GoUpgradeOptions{CurrentOnly: true}); err != nil || len(plan) != 0 {
+		t.Errorf("current only plan = %+v, %v", plan, err)
+	}
+	g.changeSymblinkGoBin("1.21.4")
+	plan, err = g.PlanGoUpgrade(GoUpgradeOptions{CurrentOnly: true})
+	if err != nil || len(plan) != 1 || !plan[0].Current || plan[0].Prune != nil {
+		t.Errorf("current only plan = %+v, %v", plan, err)
+	}
+
+	moved, err := g.moveAliases("1.21.4", "1.21.6")
+	if err != nil || len(moved) != 1 || moved[0].Version != "1.21.6" || moved[0].Selector != "1.21.6" {
+		t.Errorf("moveAliases = %+v, %v", moved, err)
+	}
+}
+
+func TestUpgradeGoPrune(t *testing.T) {
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	home, projects := t.TempDir(), t.TempDir()
+	for _, v := range []string{"1.20.1", "1.20.2", "1.21.4", "1.21.5", "1.21.6"} {
+		if err := os.MkdirAll(filepath.Join(home, "versions", v, "go", "bin"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(projects, pinFile), []byte("1.21.4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOVM_PROJECT_DIRS", projects)
+	// nothing answers, installs fail
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	g, err := NewGoVmWithOptions(Options{Home: home, Registry: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.20.1")
+	if _, err := g.SetAlias("legacy", "1.20.2"); err != nil {
+		t.Fatal(err)
+	}
+	defer func(tags map[string][]string) { githubTags = tags }(githubTags)
+	githubTags = map[string][]string{"golang/go": {"go1.20.1", "go1.20.2", "go1.20.3", "go1.21.4", "go1.21.5", "go1.21.6"}}
+
+	plan, err := g.PlanGoUpgrade(GoUpgradeOptions{Prune: true})
+	if err != nil || len(plan) != 2 {
+		t.Fatalf("plan = %+v, %v", plan, err)
+	}
+	// 1.21.4 is pinned exactly
+	if !reflect.DeepEqual(plan[1].Prune, []string{"1.21.5"}) {
+		t.Errorf("prune = %v", plan[1].Prune)
+	}
+
+	// the current version, pins and aliases are kept
+	decisions, err := g.PlanPrune(PrunePolicy{KeepPinned: []string{projects}, Superseded: []string{"1.20.1", "1.20.2", "1.21.4", "1.21.5"}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, d := range decisions {
+		if d.Remove {
+			got[d.Version] = "remove"
+		} else {
+			got[d.Version] = d.Reason
+		}
+	}
+	want := map[string]string{"1.20.1": PruneCurrent, "1.20.2": PruneAliased, "1.21.4": PrunePinned, "1.21.5": "remove"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decisions = %v, want %v", got, want)
+	}
+
+	// the install of 1.20.3 fails, nothing is switched or removed
+	if err := g.UpgradeGo(plan); err == nil {
+		t.Fatal("upgrade succeeded without the new patch")
+	}
+	if g.CurrentVersion() != "1.20.1" {
+		t.Errorf("current version switched to %s", g.CurrentVersion())
+	}
+	if versions, _ := g.InstalledVersions(false); len(versions) != 5 {
+		t.Errorf("versions = %+v", versions)
+	}
+
+	// 1.21.6 is installed, the upgrade of 1.21 prunes through PlanPrune
+	if err := g.UpgradeGo(plan[1:]); err != nil {
+		t.Fatal(err)
+	}
+	for v, want := range map[string]bool{"1.20.1": true, "1.20.2": true, "1.21.4": true, "1.21.5": false, "1.21.6": true} {
+		if _, _, ok := g.findVersion(v); ok != want {
+			t.Errorf("%s installed = %v, want %v", v, ok, want)
+		}
+	}
+}
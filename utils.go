@@ -1,11 +1,15 @@
 package govm
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
@@ -22,7 +26,7 @@ func DownloadWithProgress(url string, tarName string, destFolder string) (err er
 	if err != nil {
 		return err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -31,22 +35,72 @@ func DownloadWithProgress(url string, tarName string, destFolder string) (err er
 		_ = Body.Close()
 	}(resp.Body)
 
-	f, _ := os.OpenFile(destTarPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(T("download.bad_status", resp.Status, url))
+	}
+
+	f, err := os.OpenFile(destTarPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	traceFS("create", destTarPath, err)
+	if err != nil {
+		return err
+	}
 	defer func(f *os.File) {
 		_ = f.Close()
 	}(f)
 
-	bar := progressbar.DefaultBytes(
-		resp.ContentLength,
-		"Downloading",
-	)
-	_, err = io.Copy(io.MultiWriter(f, bar), resp.Body)
+	var w io.Writer = f
+	if reporter.Progress {
+		w = io.MultiWriter(f, progressbar.NewOptions64(
+			resp.ContentLength,
+			progressbar.OptionSetDescription("Downloading"),
+			progressbar.OptionSetWriter(reporter.Err),
+			progressbar.OptionShowBytes(true),
+			progressbar.OptionSetWidth(10),
+			progressbar.OptionThrottle(65*time.Millisecond),
+			progressbar.OptionShowCount(),
+			progressbar.OptionOnCompletion(func() {
+				_, _ = fmt.Fprint(reporter.Err, "\n")
+			}),
+			progressbar.OptionSpinnerType(14),
+			progressbar.OptionFullWidth(),
+		))
+	}
+	_, err = io.Copy(w, resp.Body)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// writeFileAtomic writes data to a new temporary file next to path and renames
+// it over path, so readers never see half a file and concurrent writers never
+// write into the same temporary file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 func BytesToString(data []byte) string {
 	return string(data[:])
 }
@@ -62,31 +116,36 @@ func Find(slice []string, val string) bool {
 }
 
 func Successf(format string, a ...interface{}) {
-	_, _ = ColorSuccess.Printf(format, a...)
+	reporter.Successf(format, a...)
 }
 
 func Infof(format string, a ...interface{}) {
-	_, _ = ColorInfo.Printf(format, a...)
+	reporter.Infof(format, a...)
+}
+
+// Debugf is only printed in verbose mode
+func Debugf(format string, a ...interface{}) {
+	reporter.Debugf(format, a...)
 }
 
 func Errorf(format string, a ...interface{}) {
-	_, _ = ColorError.Printf(format, a...)
+	reporter.Errorf(format, a...)
 }
 
 func Major(a ...interface{}) {
-	_, _ = ColorMajorVersion.Print(a...)
+	reporter.Resultf(ColorMajorVersion, "%s", fmt.Sprint(a...))
 }
 
 func Successln(a ...interface{}) {
-	_, _ = ColorSuccess.Println(a...)
+	reporter.Successf("%s", fmt.Sprintln(a...))
 }
 
 func Infoln(a ...interface{}) {
-	_, _ = ColorInfo.Println(a...)
+	reporter.Infof("%s", fmt.Sprintln(a...))
 }
 
 func Errorln(a ...interface{}) {
-	_, _ = ColorError.Println(a...)
+	reporter.Errorf("%s", fmt.Sprintln(a...))
 }
 
 func CheckError(err error, format string) {
@@ -0,0 +1,198 @@
+package govm
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// VerifyResult compares an installed version with its manifest, the paths
+// are relative to Path and slash separated
+type VerifyResult struct {
+	Version  string   `json:"version"`
+	Path     string   `json:"path"`
+	Store    string   `json:"store"`
+	Missing  []string `json:"missing"`
+	Modified []string `json:"modified"`
+	Extra    []string `json:"extra"`
+	// NoManifest is set for versions installed before manifests were recorded
+	NoManifest bool `json:"no_manifest"`
+}
+
+// OK tells whether the version matches its manifest
+func (r VerifyResult) OK() bool {
+	return !r.NoManifest && len(r.Missing)+len(r.Modified)+len(r.Extra) == 0
+}
+
+// Verify checks the installation of version found first in the stores
+func (g *GoVM) Verify(version string) (VerifyResult, error) {
+	version = normalizeVersion(version)
+	dir, store, ok := g.findVersion(version)
+	if !ok {
+		return VerifyResult{}, errors.New(T("exec.not_installed", version))
+	}
+	return g.verifyDir(version, dir, store)
+}
+
+// VerifyAll checks every installed version of every store
+func (g *GoVM) VerifyAll() ([]VerifyResult, error) {
+	versions, err := g.InstalledVersions(false)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]VerifyResult, 0, len(versions))
+	for _, v := range versions {
+		r, err := g.verifyDir(v.Version, v.Path, v.Store)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// verifyDir hashes every file of dir and compares it with the manifest,
+// a file whose size, mode or content changed is modified
+func (g *GoVM) verifyDir(version, dir, store string) (VerifyResult, error) {
+	r := VerifyResult{Version: version, Path: dir, Store: store, Missing: []string{}, Modified: []string{}, Extra: []string{}}
+	want, err := readManifest(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		r.NoManifest = true
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	// only the listing is needed here, the hashes of the files in the
+	// manifest are compared below
+	got := make(map[string]os.FileInfo)
+	err = walkGoTree(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		got[filepath.ToSlash(rel)] = info
+		return nil
+	})
+	if err != nil {
+		return r, err
+	}
+
+	modified := make([]bool, len(want.Files))
+	g.forEachParallel(len(want.Files), func(i int) {
+		f := want.Files[i]
+		info, ok := got[f.Path]
+		if !ok {
+			return
+		}
+		if info.Size() != f.Size || info.Mode().Perm() != f.Mode {
+			modified[i] = true
+			return
+		}
+		sum, err := fileSHA256(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		modified[i] = err != nil || sum != f.SHA256
+	})
+	listed := make(map[string]bool, len(want.Files))
+	for i, f := range want.Files {
+		listed[f.Path] = true
+		if _, ok := got[f.Path]; !ok {
+			r.Missing = append(r.Missing, f.Path)
+		} else if modified[i] {
+			r.Modified = append(r.Modified, f.Path)
+		}
+	}
+	for path := range got {
+		if !listed[path] {
+			r.Extra = append(r.Extra, path)
+		}
+	}
+	sort.Strings(r.Extra)
+	return r, nil
+}
+
+// damagedFiles are the files of version verify finds modified or missing
+func (g *GoVM) damagedFiles(version string) map[string]bool {
+	damaged := make(map[string]bool)
+	r, err := g.Verify(version)
+	if err != nil {
+		return damaged
+	}
+	for _, path := range append(r.Modified, r.Missing...) {
+		damaged[path] = true
+	}
+	return damaged
+}
+
+// Repair reinstalls version from the kept archive when its checksum matches
+// the receipt, or else from the mirrors. The damaged tree is only replaced
+// once the new one is staged and runs.
+func (g *GoVM) Repair(version string) error {
+	version = normalizeVersion(version)
+	_, store, ok := g.findVersion(version)
+	if !ok {
+		return errors.New(T("exec.not_installed", version))
+	}
+	if store == StoreSystem && !g.system {
+		return errors.New(T("repair.system_version", version))
+	}
+	if dir, _, _ := g.findVersion(version); linkedTree(dir) {
+		return errors.New(T("repair.linked", version))
+	}
+	if g.system {
+		if err := g.checkSystemStore(); err != nil {
+			return err
+		}
+	}
+
+	g.mkdirs()
+	g.cleanStaleStaging()
+	unlock, err := g.lockVersion(version)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	receipt, _ := g.readReceipt(version)
+	InfoT("repair.reinstalling", version)
+	// the damaged files may share a damaged blob, they get their own copy
+	staging, err := g.stageVersion(version, receipt.SHA256, g.damagedFiles(version))
+	if err != nil {
+		return errors.New(T("repair.failed", version, err))
+	}
+
+	// swap the trees, cleanStaleStaging finishes the swap when killed in between
+	dir := g.getVersionDir(version)
+	backup := filepath.Join(g.versionsDir, repairPrefix+version)
+	_ = os.RemoveAll(backup)
+	err = os.Rename(dir, backup)
+	traceFS("rename", dir, err, "target", backup)
+	if err != nil {
+		_ = os.RemoveAll(staging)
+		return err
+	}
+	if err := g.commitStaging(staging, version); err != nil {
+		_ = os.Rename(backup, dir)
+		return errors.New(T("repair.failed", version, err))
+	}
+	err = os.RemoveAll(backup)
+	traceFS("remove", backup, err)
+	// the damaged files may have been the last links to some blobs
+	if removed, freed, err := g.CollectGarbage(); err == nil && removed > 0 {
+		InfoT("store.gc", removed, FormatBytes(freed))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	dir := filepath.Join(home, "versions", "1.17.6")
+	files := map[string]string{
+		"go/VERSION":          "go1.17.6",
+		"go/bin/go":           "#!/bin/sh",
+		"go/src/fmt/print.go": "package fmt",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(home, "versions", "1.16", "go"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.finishInstall("1.17.6", dir, nil)
+
+	if r, err := g.Verify("1.17.6"); err != nil || !r.OK() {
+		t.Fatalf("fresh install: %+v, %v", r, err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "go", "bin", "go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go", "VERSION"), []byte("go1.17.7"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go", "src", "fmt", "scan.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := g.Verify("1.17.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VerifyResult{
+		Version:  "1.17.6",
+		Path:     dir,
+		Store:    StoreUser,
+		Missing:  []string{"go/bin/go"},
+		Modified: []string{"go/VERSION"},
+		Extra:    []string{"go/src/fmt/scan.go"},
+	}
+	if !reflect.DeepEqual(r, want) || r.OK() {
+		t.Errorf("got %+v, want %+v", r, want)
+	}
+
+	results, err := g.VerifyAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !results[0].NoManifest || results[1].OK() {
+		t.Errorf("VerifyAll = %+v", results)
+	}
+	if _, err := g.Verify("1.18"); err == nil {
+		t.Error("verified a version that is not installed")
+	}
+}
@@ -0,0 +1,136 @@
+package govm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Version is a Go release name such as 1.21.3, 1.16 or 1.21rc2. Go names
+// are not semantic versions: the first release of a minor version before
+// go1.21 has no patch number, and prereleases carry no separator.
+type Version struct {
+	Major, Minor, Patch int
+	// Pre is alpha, beta or rc for a prerelease of Major.Minor, numbered by PreNum
+	Pre    string
+	PreNum int
+}
+
+var versionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:(alpha|beta|rc)(\d+))?$`)
+
+// ParseVersion parses a Go version without the go prefix
+func ParseVersion(s string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(s)
+	// prereleases come before the .0 release and have no patch number
+	if m == nil || (m[4] != "" && m[3] != "") {
+		return Version{}, errors.New(T("version.invalid", s))
+	}
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	return Version{Major: atoi(m[1]), Minor: atoi(m[2]), Patch: atoi(m[3]), Pre: m[4], PreNum: atoi(m[5])}, nil
+}
+
+var preOrder = map[string]int{"alpha": 1, "beta": 2, "rc": 3, "": 4}
+
+// Compare returns -1, 0 or 1. The prereleases of a minor version come
+// before its releases: 1.21rc1 < 1.21rc2 < 1.21.0 < 1.21.1
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{
+		v.Major - o.Major,
+		v.Minor - o.Minor,
+		preOrder[v.Pre] - preOrder[o.Pre],
+		v.PreNum - o.PreNum,
+		v.Patch - o.Patch,
+	} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Prerelease tells whether v is an alpha, beta or rc version
+func (v Version) Prerelease() bool {
+	return v.Pre != ""
+}
+
+// Stability is StabilityPrerelease or StabilityStable
+func (v Version) Stability() string {
+	if v.Prerelease() {
+		return StabilityPrerelease
+	}
+	return StabilityStable
+}
+
+// MinorVersion is the minor version v belongs to, e.g. 1.21
+func (v Version) MinorVersion() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// String is the name Go publishes v under, the one in archive names:
+// 1.16 and 1.21.0, 1 for the very first release
+func (v Version) String() string {
+	switch {
+	case v.Prerelease():
+		return fmt.Sprintf("%d.%d%s%d", v.Major, v.Minor, v.Pre, v.PreNum)
+	case v.Patch == 0 && v.Major == 1 && v.Minor == 0:
+		return "1"
+	case v.Patch == 0 && v.Major == 1 && v.Minor < 21:
+		return v.MinorVersion()
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Canonical is v as a semantic version, 1.16.0 or 1.21.0-rc.2
+func (v Version) Canonical() string {
+	if v.Prerelease() {
+		return fmt.Sprintf("%d.%d.0-%s.%d", v.Major, v.Minor, v.Pre, v.PreNum)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// sortVersionNames orders Go version names oldest first, dropping the
+// names that are no Go versions
+func sortVersionNames(names []string) []string {
+	type named struct {
+		name string
+		v    Version
+	}
+	parsed := make([]named, 0, len(names))
+	for _, name := range names {
+		if v, err := ParseVersion(name); err == nil {
+			parsed = append(parsed, named{name, v})
+		}
+	}
+	sort.SliceStable(parsed, func(i, j int) bool { return parsed[i].v.Compare(parsed[j].v) < 0 })
+	sorted := make([]string, len(parsed))
+	for i, p := range parsed {
+		sorted[i] = p.name
+	}
+	return sorted
+}
+
+func versionStability(version string) string {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return StabilityStable
+	}
+	return v.Stability()
+}
+
+// normalizeVersion maps a version to the name Go publishes it under,
+// 1.16.0 to 1.16 and 1.21 to 1.21.0, and keeps other strings as they are
+func normalizeVersion(version string) string {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return version
+	}
+	return v.String()
+}
@@ -0,0 +1,86 @@
+package govm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersionCompare(t *testing.T) {
+	ordered := []string{"1", "1.0.1", "1.9beta1", "1.9", "1.21alpha1", "1.21rc1", "1.21rc2", "1.21.0", "1.21.1", "1.22beta1"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := ParseVersion(ordered[i-1])
+		b, err := ParseVersion(ordered[i])
+		if err != nil || a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+			t.Errorf("%s < %s does not hold", ordered[i-1], ordered[i])
+		}
+	}
+	a, _ := ParseVersion("1.16")
+	b, _ := ParseVersion("1.16.0")
+	if a.Compare(b) != 0 {
+		t.Errorf("1.16 and 1.16.0 compare as %d", a.Compare(b))
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in, display, canonical, minor string
+		pre                           bool
+	}{
+		{"1", "1", "1.0.0", "1.0", false},
+		{"1.0", "1", "1.0.0", "1.0", false},
+		{"1.16", "1.16", "1.16.0", "1.16", false},
+		{"1.16.0", "1.16", "1.16.0", "1.16", false},
+		{"1.16.15", "1.16.15", "1.16.15", "1.16", false},
+		{"1.21", "1.21.0", "1.21.0", "1.21", false},
+		{"1.21.0", "1.21.0", "1.21.0", "1.21", false},
+		{"1.21rc2", "1.21rc2", "1.21.0-rc.2", "1.21", true},
+		{"1.9beta1", "1.9beta1", "1.9.0-beta.1", "1.9", true},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.in)
+		if err != nil {
+			t.Errorf("ParseVersion(%q): %v", tt.in, err)
+			continue
+		}
+		if v.String() != tt.display || v.Canonical() != tt.canonical || v.MinorVersion() != tt.minor || v.Prerelease() != tt.pre {
+			t.Errorf("ParseVersion(%q) = %s %s %s %v, want %s %s %s %v", tt.in,
+				v, v.Canonical(), v.MinorVersion(), v.Prerelease(), tt.display, tt.canonical, tt.minor, tt.pre)
+		}
+	}
+	for _, in := range []string{"", "go1.21", "1.21.0rc1", "1.21-rc1", "1.x", "latest"} {
+		if _, err := ParseVersion(in); err == nil {
+			t.Errorf("ParseVersion(%q) succeeded", in)
+		}
+	}
+}
+
+func TestSortVersionNames(t *testing.T) {
+	got := sortVersionNames([]string{"1.21.1", "1.21rc2", "tip", "1.9", "1.21.0", "1.21rc1", "1.10", "1.20.5"})
+	want := []string{"1.9", "1.10", "1.20.5", "1.21rc1", "1.21rc2", "1.21.0", "1.21.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortVersionNames = %v, want %v", got, want)
+	}
+}
+
+func TestGroupVersions(t *testing.T) {
+	groups := groupVersions([]string{"1.21.0", "1.9", "1.21rc1", "1", "1.9.1"})
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.Minor)
+		for _, v := range g.Versions {
+			got = append(got, v.Version+":"+v.Stability)
+		}
+	}
+	want := []string{"1.0", "1:stable", "1.9", "1.9:stable", "1.9.1:stable", "1.21", "1.21rc1:prerelease", "1.21.0:stable"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupVersions = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	for in, want := range map[string]string{"1.16.0": "1.16", "1.21": "1.21.0", "1.21rc1": "1.21rc1", "tip": "tip"} {
+		if got := normalizeVersion(in); got != want {
+			t.Errorf("normalizeVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// versionEnv overrides the version for a shell, it is not the version govm env prints
+const versionEnv = "GOVM_SHELL_VERSION"
+
+// Where the version for a directory comes from, highest priority first
+const (
+	OriginEnv     = "env"
+	OriginPinFile = "pin_file"
+	OriginGoMod   = "go_mod"
+	OriginGlobal  = "global"
+)
+
+// Explanation is the decision chain that picks the Go version for a directory
+type Explanation struct {
+	Dir    string `json:"dir"`
+	Origin string `json:"origin"`
+	// OriginPath is the environment variable, pin file or go.mod setting the version
+	OriginPath string `json:"origin_path,omitempty"`
+	Selector   string `json:"selector,omitempty"`
+	// Version is the installed version Selector resolves to, or Selector
+	// itself when it is exact but not installed
+	Version   string `json:"version,omitempty"`
+	Installed bool   `json:"installed"`
+	// Binary is the go binary of Version
+	Binary string `json:"binary,omitempty"`
+	// Global is the version current links to
+	Global string `json:"global,omitempty"`
+	// PathGo is the go run from PATH, and Shadowing the go binaries on PATH
+	// in front of CurrentBin, govm's current/bin
+	PathGo     string   `json:"path_go,omitempty"`
+	CurrentBin string   `json:"current_bin"`
+	OnPath     bool     `json:"on_path"`
+	Shadowing  []string `json:"shadowing,omitempty"`
+}
+
+// Explain tells which version applies in dir and why. GOVM_SHELL_VERSION
+// comes first, then the nearest .go-version, the toolchain line of the
+// nearest go.mod and last the version govm use switched to. govm use and
+// govm install without a version take the version from the same chain.
+func (g *GoVM) Explain(dir string) Explanation {
+	e := Explanation{Dir: dir, Global: g.CurrentVersion(), CurrentBin: g.currentBinDir}
+	switch {
+	case os.Getenv(versionEnv) != "":
+		e.Origin, e.OriginPath, e.Selector = OriginEnv, versionEnv, os.Getenv(versionEnv)
+	default:
+		if path, selector := findUp(dir, pinFile, readPinFile); path != "" {
+			e.Origin, e.OriginPath, e.Selector = OriginPinFile, path, selector
+		} else if path, selector := findUp(dir, "go.mod", goModToolchain); path != "" && selector != "" && selector != "default" {
+			e.Origin, e.OriginPath, e.Selector = OriginGoMod, path, selector
+		} else {
+			e.Origin, e.Selector = OriginGlobal, e.Global
+		}
+	}
+	// go1.21.3 as written in go.mod and by go version, but not an alias like gopher
+	e.Selector = strings.TrimSpace(e.Selector)
+	if len(e.Selector) > 2 && strings.HasPrefix(e.Selector, "go") && e.Selector[2] >= '0' && e.Selector[2] <= '9' {
+		e.Selector = e.Selector[2:]
+	}
+
+	if v, ok := g.aliasVersion(e.Selector); ok {
+		e.Version = v
+	} else if sel, err := ParseSelector(e.Selector); err == nil {
+		var names []string
+		if versions, err := g.InstalledVersions(false); err == nil {
+			for _, v := range versions {
+				names = append(names, v.Version)
+			}
+		}
+		if v, err := sel.Resolve(names); err == nil {
+			e.Version = v
+		} else if v, ok := sel.Exact(); ok {
+			e.Version = v
+		}
+	}
+	if dir, _, ok := g.findVersion(e.Version); ok && e.Version != "" {
+		e.Installed = true
+		e.Binary = filepath.Join(dir, "go", "bin", goBinary())
+	}
+
+	e.PathGo, e.OnPath, e.Shadowing = g.lookPathGo()
+	return e
+}
+
+// findUp reads the nearest file called name in dir or its parents, read
+// returns "" when the file does not count
+func findUp(dir, name string, read func(string) string) (string, string) {
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, read(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+func readPinFile(path string) string {
+	//#nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// lookPathGo finds the go run from PATH, whether govm's current/bin is on
+// PATH and the go binaries found in front of it
+func (g *GoVM) lookPathGo() (string, bool, []string) {
+	var first string
+	var shadowing []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		if sameDir(dir, g.currentBinDir) {
+			if first == "" {
+				first = filepath.Join(g.currentBinDir, goBinary())
+			}
+			return first, true, shadowing
+		}
+		path := filepath.Join(dir, goBinary())
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && (runtime.GOOS == "windows" || info.Mode()&0o111 != 0) {
+			if first == "" {
+				first = path
+			}
+			shadowing = append(shadowing, path)
+		}
+	}
+	// nothing is in front of govm when it is not on PATH at all
+	return first, false, nil
+}
+
+func sameDir(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
+
+// goBinary is the file name of the go command
+func goBinary() string {
+	if runtime.GOOS == "windows" {
+		return "go.exe"
+	}
+	return "go"
+}
@@ -0,0 +1,100 @@
+package govm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("GOVM_CONFIG", "")
+	t.Setenv("GOVM_SYSTEM_STORE", t.TempDir())
+	t.Setenv(versionEnv, "")
+	for _, v := range []string{"1.20.3", "1.21.1", "1.21.4"} {
+		if err := os.MkdirAll(filepath.Join(home, "versions", v, "go", "bin"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := NewGoVmWithOptions(Options{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(g.currentDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	g.changeSymblinkGoBin("1.20.3")
+
+	sub := filepath.Join(project, "cmd", "tool")
+	if err := os.MkdirAll(sub, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	goMod := filepath.Join(project, "go.mod")
+	if err := os.WriteFile(goMod, []byte("module example.com/app\n\ngo 1.21\n\ntoolchain go1.21.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(origin, path, selector, version string, installed bool) {
+		t.Helper()
+		e := g.Explain(sub)
+		if e.Origin != origin || e.OriginPath != path || e.Selector != selector || e.Version != version || e.Installed != installed {
+			t.Errorf("Explain = %s %q %q %q %v, want %s %q %q %q %v",
+				e.Origin, e.OriginPath, e.Selector, e.Version, e.Installed, origin, path, selector, version, installed)
+		}
+		if installed && e.Binary != filepath.Join(home, "versions", version, "go", "bin", goBinary()) {
+			t.Errorf("binary %s", e.Binary)
+		}
+	}
+	check(OriginGoMod, goMod, "1.21.1", "1.21.1", true)
+
+	pin := filepath.Join(project, "cmd", pinFile)
+	if err := os.WriteFile(pin, []byte("1.21.x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	check(OriginPinFile, pin, "1.21.x", "1.21.4", true)
+
+	t.Setenv(versionEnv, "go1.22.0")
+	check(OriginEnv, versionEnv, "1.22.0", "1.22.0", false)
+
+	t.Setenv(versionEnv, "")
+	if err := os.Remove(pin); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(goMod); err != nil {
+		t.Fatal(err)
+	}
+	check(OriginGlobal, "", "1.20.3", "1.20.3", true)
+}
+
+func TestLookPathGo(t *testing.T) {
+	g, err := NewGoVmWithOptions(Options{Home: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := t.TempDir()
+	shadow := filepath.Join(other, goBinary())
+	if err := os.WriteFile(shadow, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	empty := t.TempDir()
+	sep := string(os.PathListSeparator)
+
+	t.Setenv("PATH", empty+sep+other+sep+g.currentBinDir)
+	first, onPath, shadowing := g.lookPathGo()
+	if first != shadow || !onPath || !reflect.DeepEqual(shadowing, []string{shadow}) {
+		t.Errorf("shadowed: %s %v %v", first, onPath, shadowing)
+	}
+
+	t.Setenv("PATH", g.currentBinDir+sep+other)
+	first, onPath, shadowing = g.lookPathGo()
+	if first != filepath.Join(g.currentBinDir, goBinary()) || !onPath || shadowing != nil {
+		t.Errorf("first on PATH: %s %v %v", first, onPath, shadowing)
+	}
+
+	t.Setenv("PATH", other)
+	first, onPath, shadowing = g.lookPathGo()
+	if first != shadow || onPath || shadowing != nil {
+		t.Errorf("not on PATH: %s %v %v", first, onPath, shadowing)
+	}
+}